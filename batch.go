@@ -0,0 +1,281 @@
+package main
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/dimchansky/proxmark3-to-flipper/pkg/convert"
+)
+
+// Extensions of the Proxmark3 files picked up in batch mode
+var batchInputExts = map[string]bool{
+	".json": true, // Mifare card dumps
+	".eml":  true, // Proxmark3 emulator memory dumps
+	".bin":  true, // raw binary dumps
+	".mct":  true, // MIFARE Classic Tool dumps
+	".txt":  true, // saved LF reader or client output
+	".log":  true, // Proxmark3 client logs
+}
+
+// Struct describing the outcome of converting a single file
+type conversionResult struct {
+	Input      string
+	Output     string
+	Card       convert.Card
+	CacheFiles []string
+	Err        error
+}
+
+// Function that reports whether the input names a directory or a glob pattern rather than a single file
+func isBatchInput(input string) bool {
+	if strings.ContainsAny(input, "*?[") {
+		return true
+	}
+	fi, err := os.Stat(input)
+	return err == nil && fi.IsDir()
+}
+
+// Function that converts every recognized dump found in a directory or matched by a glob pattern
+// and writes the Flipper files into outDir, keeping the input basenames
+func runBatch(cfg *config) error {
+	input, outDir := cfg.InputFile, cfg.OutputFile
+	if outDir == stdioFileName {
+		return usageError("batch mode needs an output directory, not standard output")
+	}
+	if cfg.T5577File != "" {
+		return usageError("T5577 blocks can only be written for a single LF key, not in batch mode")
+	}
+
+	files, err := collectBatchInputs(input)
+	if err != nil {
+		return err
+	}
+	if len(files) == 0 {
+		return fmt.Errorf("no Proxmark3 dump files found in '%s'", input)
+	}
+	if err := checkBatchOutputs(files, cfg.FlipperUpload); err != nil {
+		return err
+	}
+
+	start := time.Now()
+	results := convertBatchFiles(files, outDir, cfg)
+	elapsed := time.Since(start)
+
+	failed := 0
+	var (
+		keys    []convert.HexData
+		outputs []string
+		reports []conversionReport
+	)
+	cardTypes := make(map[string]int)
+	for _, res := range results {
+		report := newConversionReport(res)
+		reports = append(reports, report)
+		if res.Err != nil {
+			failed++
+			continue
+		}
+		cardTypes[report.CardType]++
+		keys = convert.AppendUniqueKeys(keys, cardKeys(res.Card)...)
+		outputs = append(outputs, res.Output)
+		outputs = append(outputs, res.CacheFiles...)
+	}
+
+	if cfg.DryRun {
+		if err := reportDryRunExtras(cfg); err != nil {
+			return err
+		}
+	} else {
+		if cfg.FlipperUpload && len(outputs) > 0 {
+			if err := uploadToFlipper(cfg.FlipperPort, outputs); err != nil {
+				return err
+			}
+		}
+
+		if cfg.KeysFile != "" {
+			if err := writeKeysFile(cfg, keys); err != nil {
+				return err
+			}
+		}
+	}
+
+	if warningsEnabled() {
+		verb := "converted"
+		if cfg.DryRun {
+			verb = "checked"
+		}
+		_, _ = fmt.Fprintf(os.Stderr, "%s %d of %d files, %d failed, in %v (%v per file, %d jobs)\n",
+			verb, len(files)-failed, len(files), failed, elapsed.Round(time.Millisecond),
+			(elapsed / time.Duration(len(files))).Round(time.Microsecond), cfg.Jobs)
+		printCardTypes(cardTypes)
+	}
+	if cfg.Report == reportJSON {
+		if err := printReport(batchReport{Files: reports, Converted: len(files) - failed, Failed: failed}); err != nil {
+			return err
+		}
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d of %d files failed to convert", failed, len(files))
+	}
+	return nil
+}
+
+// Function that converts the batch inputs with a pool of cfg.Jobs workers, printing a progress line
+// as every file completes, and returns the results in the order of the inputs
+func convertBatchFiles(files []batchInput, outDir string, cfg *config) []conversionResult {
+	results := make([]conversionResult, len(files))
+	jobs := make(chan int)
+
+	var (
+		wg       sync.WaitGroup
+		mu       sync.Mutex
+		finished int
+	)
+	for w := 0; w < cfg.Jobs; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				start := time.Now()
+				res := convertBatchFile(files[i], outDir, cfg)
+				took := time.Since(start).Round(time.Microsecond)
+				results[i] = res
+
+				mu.Lock()
+				finished++
+				if res.Err != nil {
+					_, _ = fmt.Fprintf(os.Stderr, "[%d/%d] FAIL %s: %v (%v)\n", finished, len(files), res.Input, res.Err, took)
+				} else if warningsEnabled() {
+					_, _ = fmt.Fprintf(os.Stderr, "[%d/%d] OK   %s -> %s (%v)\n", finished, len(files), res.Input, res.Output, took)
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+	for i := range files {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+
+	return results
+}
+
+// Function that prints how many files of every card type a batch converted
+func printCardTypes(cardTypes map[string]int) {
+	names := make([]string, 0, len(cardTypes))
+	for name := range cardTypes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		_, _ = fmt.Fprintf(os.Stderr, "  %-20s %d\n", name, cardTypes[name])
+	}
+}
+
+// Struct pairing a batch input file with its path relative to the batch root
+type batchInput struct {
+	Path string
+	Rel  string
+}
+
+// Function that lists the dump files to convert, walking directories recursively
+func collectBatchInputs(input string) ([]batchInput, error) {
+	var files []batchInput
+
+	if fi, err := os.Stat(input); err == nil && fi.IsDir() {
+		err = filepath.WalkDir(input, func(path string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			if d.IsDir() || !batchInputExts[strings.ToLower(filepath.Ext(path))] {
+				return nil
+			}
+			rel, err := filepath.Rel(input, path)
+			if err != nil {
+				return err
+			}
+			files = append(files, batchInput{Path: path, Rel: rel})
+			return nil
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to walk directory '%s': %w", input, err)
+		}
+		return files, nil
+	}
+
+	matches, err := filepath.Glob(input)
+	if err != nil {
+		return nil, fmt.Errorf("invalid glob pattern '%s': %w", input, err)
+	}
+	for _, m := range matches {
+		if fi, err := os.Stat(m); err == nil && fi.Mode().IsRegular() {
+			files = append(files, batchInput{Path: m, Rel: filepath.Base(m)})
+		}
+	}
+	return files, nil
+}
+
+// Function that checks that no two batch inputs would be written to the same output file, which happens
+// when x.json and x.eml share a directory or when a glob matches x.json in two directories. The upload to
+// the Flipper puts all the files of an app in one directory, so with flipperUpload the subdirectories of the
+// inputs are ignored. Names are compared without case since Windows and macOS file systems ignore it
+func checkBatchOutputs(files []batchInput, flipperUpload bool) error {
+	stem := func(name string) string { return strings.ToLower(strings.TrimSuffix(name, filepath.Ext(name))) }
+	local := make(map[string]string, len(files))
+	uploaded := make(map[string]string, len(files))
+	for _, in := range files {
+		if prev, ok := local[stem(in.Rel)]; ok {
+			return fmt.Errorf("'%s' and '%s' would be converted to the same output file, rename one of them", prev, in.Path)
+		}
+		local[stem(in.Rel)] = in.Path
+
+		if !flipperUpload {
+			continue
+		}
+		if prev, ok := uploaded[stem(filepath.Base(in.Rel))]; ok {
+			return fmt.Errorf("'%s' and '%s' would be uploaded to the same file on the Flipper, rename one of them", prev, in.Path)
+		}
+		uploaded[stem(filepath.Base(in.Rel))] = in.Path
+	}
+	return nil
+}
+
+// Function that converts a single batch input and writes the result under outDir
+func convertBatchFile(in batchInput, outDir string, cfg *config) conversionResult {
+	res := conversionResult{Input: in.Path}
+
+	card, err := parseProxMark3File(in.Path, cfg.parseOptions())
+	if err != nil {
+		res.Err = err
+		return res
+	}
+	if res.Err = applyEdits(cfg, card); res.Err != nil {
+		return res
+	}
+	res.Card = card
+	reportCard(in.Path, card, cfg.writeOptions())
+
+	res.Output = filepath.Join(outDir, strings.TrimSuffix(in.Rel, filepath.Ext(in.Rel))+cfg.outputExt(card))
+	if cfg.DryRun {
+		res.Err = reportDryRun(cfg, in.Path, res.Output, card)
+		return res
+	}
+	if err := os.MkdirAll(filepath.Dir(res.Output), 0o755); err != nil {
+		res.Err = fmt.Errorf("failed to create output directory: %w", err)
+		return res
+	}
+	if res.Err = writeConvertedFile(cfg, in.Path, res.Output, card); res.Err != nil {
+		return res
+	}
+	if cfg.EmulationCache {
+		res.CacheFiles, res.Err = writeEmulationCache(res.Output, card, cfg.writeOptions(), cfg.Force)
+	}
+	return res
+}
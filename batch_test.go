@@ -0,0 +1,38 @@
+package main
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestCheckBatchOutputs(t *testing.T) {
+	tests := []struct {
+		name          string
+		rels          []string
+		flipperUpload bool
+		wantErr       string
+	}{
+		{"distinct names", []string{"a.json", "b.json"}, false, ""},
+		{"same stem", []string{"card.json", "card.eml"}, false, "converted to the same output file"},
+		{"same stem without case", []string{"Card.json", "card.bin"}, false, "converted to the same output file"},
+		{"subdirectories", []string{filepath.Join("a", "card.json"), filepath.Join("b", "card.json")}, false, ""},
+		{"subdirectories uploaded", []string{filepath.Join("a", "card.json"), filepath.Join("b", "card.json")}, true, "uploaded to the same file on the Flipper"},
+		{"distinct names uploaded", []string{filepath.Join("a", "card.json"), filepath.Join("b", "key.txt")}, true, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var files []batchInput
+			for _, rel := range tt.rels {
+				files = append(files, batchInput{Path: filepath.Join("dumps", rel), Rel: rel})
+			}
+			err := checkBatchOutputs(files, tt.flipperUpload)
+			switch {
+			case tt.wantErr == "" && err != nil:
+				t.Fatalf("unexpected error: %v", err)
+			case tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)):
+				t.Fatalf("got error %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
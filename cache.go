@@ -0,0 +1,70 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/dimchansky/proxmark3-to-flipper/pkg/convert"
+)
+
+// Mutex serializing the writes of the emulation cache, and the key cache files written so far in this run
+var (
+	emulationCacheMu sync.Mutex
+	keyCachesWritten = make(map[string]bool)
+)
+
+// Function that returns the names of the shadow file and the key cache file written next to an output
+func emulationCacheFiles(outFile string, mc *convert.MifareCard) (string, string) {
+	shadowFile := strings.TrimSuffix(outFile, filepath.Ext(outFile)) + ".shd"
+	keysFile := filepath.Join(filepath.Dir(outFile), ".cache", mc.KeyCacheName())
+	return shadowFile, keysFile
+}
+
+// Function that reports whether a key cache file was written for another dump in this run
+func keyCacheWritten(name string) bool {
+	emulationCacheMu.Lock()
+	defer emulationCacheMu.Unlock()
+	return keyCachesWritten[name]
+}
+
+// Function that writes the companion files Flipper uses when emulating a Mifare Classic card next to
+// its .nfc file: the .shd shadow file and the key cache in .cache, laid out like /ext/nfc on the SD card.
+// It returns the names of the files written. Existing files are only replaced with force, except the key
+// cache written for another dump of the same card in this run, since the dumps of a card share it.
+func writeEmulationCache(outFile string, c convert.Card, opts convert.WriteOptions, force bool) ([]string, error) {
+	// batch workers converting dumps of the same card share its key cache file
+	emulationCacheMu.Lock()
+	defer emulationCacheMu.Unlock()
+
+	mc, ok := c.(*convert.MifareCard)
+	if !ok {
+		if warningsEnabled() {
+			_, _ = fmt.Fprintf(os.Stderr, "warning: %s: no emulation cache for %s files\n", outFile, c.FlipperExt())
+		}
+		return nil, nil
+	}
+
+	shadowFile, keysFile := emulationCacheFiles(outFile, mc)
+	opts.Format = convert.OutputFlipper
+	if err := writeOutputFile(shadowFile, mc, opts, force); err != nil {
+		return nil, err
+	}
+
+	cacheDir := filepath.Dir(keysFile)
+	if err := os.MkdirAll(cacheDir, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create key cache directory '%s': %w", cacheDir, err)
+	}
+	err := writeFileAtomic(keysFile, "key cache file", force || keyCachesWritten[keysFile], func(w io.Writer) error {
+		return convert.WriteFlipperKeyCache(w, mc)
+	})
+	if err != nil {
+		return nil, err
+	}
+	keyCachesWritten[keysFile] = true
+
+	return []string{shadowFile, keysFile}, nil
+}
@@ -0,0 +1,236 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	"github.com/dimchansky/proxmark3-to-flipper/pkg/convert"
+)
+
+// Function that runs the convert mode: converts a dump, or a directory of dumps, into Flipper files
+func runConvert(args []string) error {
+	cfg, err := parseConvertArgs(args, flag.ExitOnError)
+	if err != nil {
+		return err
+	}
+
+	if isBatchInput(cfg.InputFile) {
+		return runBatch(cfg)
+	}
+
+	res := convertFile(cfg)
+	if cfg.Report == reportJSON {
+		if err := printReport(newConversionReport(res)); err != nil {
+			return err
+		}
+	}
+	if res.Err != nil {
+		return res.Err
+	}
+	if cfg.DryRun {
+		return reportDryRunExtras(cfg)
+	}
+	outputs := append([]string{res.Output}, res.CacheFiles...)
+
+	if cfg.FlipperUpload {
+		if err := uploadToFlipper(cfg.FlipperPort, outputs); err != nil {
+			return err
+		}
+	}
+
+	if cfg.T5577File != "" {
+		if err := writeT5577File(cfg, res.Card); err != nil {
+			return err
+		}
+	}
+
+	if cfg.KeysFile != "" {
+		return writeKeysFile(cfg, cardKeys(res.Card))
+	}
+	return nil
+}
+
+// Function that converts the single input file named on the command line
+func convertFile(cfg *config) conversionResult {
+	res := conversionResult{Input: cfg.InputFile, Output: cfg.OutputFile}
+
+	card, err := parseProxMark3File(cfg.InputFile, cfg.parseOptions())
+	if err != nil {
+		res.Err = err
+		return res
+	}
+	if res.Err = applyEdits(cfg, card); res.Err != nil {
+		return res
+	}
+	res.Card = card
+	reportCard(cfg.InputFile, card, cfg.writeOptions())
+
+	// without -o the output is named after the input, in the output directory
+	switch {
+	case cfg.dropped:
+		res.Output = droppedOutputName(cfg, card)
+	case res.Output == "":
+		base := filepath.Base(cfg.InputFile)
+		res.Output = filepath.Join(cfg.OutputDir, strings.TrimSuffix(base, filepath.Ext(base))+cfg.outputExt(card))
+	}
+	if cfg.DryRun {
+		res.Err = reportDryRun(cfg, cfg.InputFile, res.Output, card)
+		return res
+	}
+	if cfg.OutputFile == "" {
+		if res.Err = os.MkdirAll(cfg.OutputDir, 0o755); res.Err != nil {
+			return res
+		}
+	}
+
+	if res.Err = writeConvertedFile(cfg, cfg.InputFile, res.Output, card); res.Err != nil {
+		return res
+	}
+	if cfg.EmulationCache {
+		res.CacheFiles, res.Err = writeEmulationCache(res.Output, card, cfg.writeOptions(), cfg.Force)
+	}
+	return res
+}
+
+// Function to parse the command line arguments of the convert mode and return a config struct. With
+// flag.ContinueOnError a bad flag is returned as an error instead of exiting the program, and the flags
+// named in skipDefaults take no default from the configuration file or the environment
+func parseConvertArgs(args []string, errorHandling flag.ErrorHandling, skipDefaults ...string) (*config, error) {
+	var cfg config
+	fs := flag.NewFlagSet("convert", errorHandling)
+	fs.StringVar(&cfg.InputFile, "i", "", "input Proxmark3 dump file in JSON format or LF reader output, '-' for stdin (a directory or glob pattern converts in batch)")
+	fs.StringVar(&cfg.OutputFile, "o", "", "output Flipper file in NFC or RFID format or Proxmark3 dump (see -output-format), '-' for stdout (a directory in batch mode)")
+	fs.StringVar(&cfg.OutputDir, "output-dir", "", "directory the output is written to when -o is not given, named after the input")
+	fs.IntVar(&cfg.FormatVersion, "format-version", convert.NFCFormatLatest, "Flipper NFC file format version to write (2, 3 or 4)")
+	fs.BoolVar(&cfg.SwapATQA, "swap-atqa", false, "swap the ATQA bytes of the dump instead of detecting their order")
+	fs.StringVar(&cfg.InputFormat, "input-format", string(convert.FormatAuto), "input format, detected from the content by default: "+inputFormatList())
+	fs.BoolVar(&cfg.Strict, "strict", false, "reject JSON dumps which are not written the way the official Proxmark3 client writes them")
+	fs.BoolVar(&cfg.Lenient, "lenient", false, "accept JSON dumps of Proxmark3 forks: hex with spaces, missing headers, blocks keyed as \"Block N\"")
+	fs.BoolVar(&cfg.Verbose, "v", false, "log what is parsed and written to standard error")
+	fs.BoolVar(&cfg.Debug, "vv", false, "also log the decisions of the parser: detected format, ATQA byte order, card size, missing blocks")
+	fs.BoolVar(&cfg.Quiet, "quiet", false, "only print errors, no warnings or hints")
+	fs.StringVar(&cfg.OutputFormat, "output-format", "", "output format: "+outputFormatList()+", by default chosen by the output file extension")
+	fs.StringVar(&cfg.KeysFile, "keys", "", "also write the unique sector keys of the dump to this key dictionary file, '-' for stdout")
+	fs.StringVar(&cfg.KeysFormat, "keys-format", "", "key dictionary format: pm3 (.dic) or flipper (mf_classic_dict_user.nfc), by default chosen by the keys file extension")
+	fs.BoolVar(&cfg.FlipperUpload, "flipper-upload", false, "upload the converted files to a Flipper Zero connected over USB")
+	fs.StringVar(&cfg.FlipperPort, "flipper-port", "", "serial port of the Flipper Zero, detected when empty")
+	fs.BoolVar(&cfg.EmulationCache, "cache", false, "also write the .shd shadow file and the .cache key cache Flipper uses to emulate Mifare Classic cards")
+	fs.BoolVar(&cfg.AnnotateAccess, "annotate-access", false, "precede every sector trailer of the Flipper file with comments decoding its access conditions")
+	fs.StringVar(&cfg.SetUID, "set-uid", "", "change the UID of a Mifare Classic card, rewriting block 0 and its BCC")
+	fs.Var(&cfg.SetKeysA, "set-key-a", "replace Key A in the sector trailers, `[SECTOR:]KEY` for one or every sector (repeatable)")
+	fs.Var(&cfg.SetKeysB, "set-key-b", "replace Key B in the sector trailers, `[SECTOR:]KEY` for one or every sector (repeatable)")
+	fs.Var(&cfg.SetValues, "set-value", "rewrite a Mifare Classic data block as a value block, `BLOCK=VALUE` (repeatable)")
+	fs.StringVar(&cfg.T5577File, "t5577", "", "also write the T5577 blocks cloning an LF key to this file, '-' for stdout")
+	fs.StringVar(&cfg.T5577Format, "t5577-format", string(convert.T5577Proxmark3), "T5577 blocks format: pm3 (lf t55xx write commands) or blocks")
+	fs.IntVar(&cfg.Jobs, "jobs", runtime.NumCPU(), "number of files converted in parallel in batch mode")
+	fs.BoolVar(&cfg.Provenance, "provenance", false, "add comments naming the source dump, its SHA-256, the tool version and the conversion time to Flipper files")
+	fs.BoolVar(&cfg.Checksum, "sha256", false, "also write a OUTPUT.sha256 checksum file next to every output, as read by sha256sum -c")
+	fs.StringVar(&cfg.Template, "template", "", "render the card through a Go text/template file instead of writing a Flipper file, or an embedded template: "+embeddedTemplateList())
+	fs.StringVar(&cfg.Report, "report", "", "print a machine-readable conversion report to stdout: json")
+	fs.BoolVar(&cfg.DryRun, "n", false, "dry run: parse and check the dumps and print what would be written, without creating any file")
+	fs.BoolVar(&cfg.DryRun, "dry-run", false, "same as -n")
+	fs.BoolVar(&cfg.Force, "f", false, "overwrite existing output files, which are left untouched otherwise")
+	fs.BoolVar(&cfg.Force, "force", false, "same as -f")
+
+	fs.Usage = func() {
+		_, _ = fmt.Fprintf(fs.Output(), "Usage of %s convert [flags] [DUMP]:\n", os.Args[0])
+		fs.PrintDefaults()
+	}
+	usage = fs.Usage
+	if err := applyDefaults(fs, skipDefaults...); err != nil {
+		return nil, err
+	}
+	if err := fs.Parse(args); err != nil {
+		return nil, err
+	}
+
+	var err error
+	if cfg.InputFile, err = inputArg(fs, cfg.InputFile); err != nil {
+		return nil, err
+	}
+	if cfg.InputFile == "" {
+		return nil, usageError("please provide input Proxmark3 dump file in JSON format or LF reader output")
+	}
+
+	if cfg.OutputFile == "" {
+		switch {
+		case cfg.OutputDir == "":
+			return nil, usageError("please provide output Flipper file in NFC or RFID format")
+		case isBatchInput(cfg.InputFile):
+			cfg.OutputFile = cfg.OutputDir
+		case cfg.InputFile == stdioFileName:
+			return nil, usageError("please provide output file name with -o when reading standard input")
+		}
+	}
+
+	if err := cfg.checkOutputFormat(); err != nil {
+		return nil, err
+	}
+
+	if cfg.Template != "" {
+		if cfg.OutputFormat != "" {
+			return nil, usageError("-template and -output-format cannot be used together")
+		}
+		if cfg.template, cfg.templateExt, err = loadTemplate(cfg.Template); err != nil {
+			return nil, err
+		}
+	}
+
+	if cfg.Jobs < 1 {
+		return nil, usageError(fmt.Sprintf("invalid number of jobs %d, expecting at least 1", cfg.Jobs))
+	}
+
+	if (cfg.FlipperUpload || cfg.EmulationCache) && (cfg.outputFormat() != convert.OutputFlipper || cfg.template != nil) {
+		return nil, usageError("uploading to the Flipper and writing the emulation cache need Flipper output files")
+	}
+
+	if cfg.FlipperUpload && cfg.OutputFile == stdioFileName {
+		return nil, usageError("cannot upload to the Flipper when writing to standard output")
+	}
+
+	if cfg.EmulationCache && cfg.OutputFile == stdioFileName {
+		return nil, usageError("cannot write the emulation cache when writing to standard output")
+	}
+
+	if err := cfg.checkInputFormat(); err != nil {
+		return nil, err
+	}
+
+	if err := cfg.applyLogLevel(); err != nil {
+		return nil, err
+	}
+
+	switch convert.T5577Format(cfg.T5577Format) {
+	case convert.T5577Proxmark3, convert.T5577Blocks:
+	default:
+		return nil, usageError(fmt.Sprintf("unsupported T5577 blocks format '%s', expecting pm3 or blocks", cfg.T5577Format))
+	}
+
+	switch cfg.Report {
+	case "":
+	case reportJSON:
+		if cfg.OutputFile == stdioFileName || cfg.KeysFile == stdioFileName || cfg.T5577File == stdioFileName {
+			return nil, usageError("cannot print the report to standard output when writing files to it")
+		}
+	default:
+		return nil, usageError(fmt.Sprintf("unsupported report format '%s', expecting json", cfg.Report))
+	}
+
+	switch convert.DictFormat(cfg.KeysFormat) {
+	case "", convert.DictProxmark3, convert.DictFlipper:
+	default:
+		return nil, usageError(fmt.Sprintf("unsupported key dictionary format '%s', expecting pm3 or flipper", cfg.KeysFormat))
+	}
+
+	switch cfg.FormatVersion {
+	case convert.NFCFormatV2, convert.NFCFormatV3, convert.NFCFormatV4:
+	default:
+		return nil, usageError(fmt.Sprintf("unsupported Flipper NFC format version %d, expecting 2, 3 or 4", cfg.FormatVersion))
+	}
+
+	return &cfg, nil
+}
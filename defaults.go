@@ -0,0 +1,197 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"flag"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// Prefix of the environment variables setting flag defaults, e.g. PM3TOFLIPPER_FORMAT_VERSION for -format-version
+const envPrefix = "PM3TOFLIPPER_"
+
+// Commands reading each key of the configuration file, named like their flag. The keys set the flag of every
+// command which has it, so a shared key such as strict applies to each of them with that command's meaning.
+// The flags choosing the input and output of a run, -i, -o, -n and -f, are no defaults and have no key
+var configKeys = map[string][]string{
+	"access":          {"validate"},
+	"annotate-access": {"convert"},
+	"cache":           {"convert"},
+	"client":          {"read"},
+	"cmd":             {"read"},
+	"color":           {"diff", "tui"},
+	"flipper-port":    {"convert"},
+	"flipper-upload":  {"convert"},
+	"format-version":  {"convert", "merge", "read", "tui"},
+	"input-format":    {"convert", "diff", "info", "keys", "merge", "ndef", "tui", "validate"},
+	"jobs":            {"convert", "serve"},
+	"json":            {"diff", "info", "ndef"},
+	"keys":            {"convert"},
+	"keys-format":     {"convert", "keys"},
+	"lenient":         {"convert", "diff", "info", "keys", "merge", "ndef", "tui", "validate"},
+	"listen":          {"serve"},
+	"max-size":        {"serve"},
+	"output-dir":      {"convert", "tui"},
+	"output-format":   {"convert", "merge", "read"},
+	"port":            {"read"},
+	"provenance":      {"convert"},
+	"quiet":           {"convert", "diff", "info", "keys", "merge", "ndef", "read", "serve", "validate"},
+	"report":          {"convert"},
+	"set-key-a":       {"convert"},
+	"set-key-b":       {"convert"},
+	"set-uid":         {"convert"},
+	"set-value":       {"convert"},
+	"sha256":          {"convert"},
+	"strict":          {"convert", "diff", "info", "keys", "merge", "ndef", "tui", "validate"},
+	"swap-atqa":       {"convert", "diff", "info", "keys", "merge", "read", "tui", "validate"},
+	"t5577":           {"convert"},
+	"t5577-format":    {"convert"},
+	"template":        {"convert"},
+	"timeout":         {"serve"},
+	"v":               {"convert", "diff", "info", "keys", "merge", "ndef", "read", "serve", "validate"},
+	"vv":              {"convert", "diff", "info", "keys", "merge", "ndef", "read", "serve", "validate"},
+}
+
+// Struct representing a value of the configuration file with the line it was read from
+type configValue struct {
+	Value string
+	Line  int
+}
+
+// Function that returns the path of the configuration file: $PM3TOFLIPPER_CONFIG, or config.yaml in
+// the pm3toflipper directory of $XDG_CONFIG_HOME, ~/.config by default
+func configFilePath() string {
+	if path := os.Getenv(envPrefix + "CONFIG"); path != "" {
+		return path
+	}
+	dir := os.Getenv("XDG_CONFIG_HOME")
+	if dir == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return ""
+		}
+		dir = filepath.Join(home, ".config")
+	}
+	return filepath.Join(dir, "pm3toflipper", "config.yaml")
+}
+
+// Function that reads the "key: value" lines of a YAML configuration file, skipping comments,
+// removing the quotes around values and expanding ~/. A missing file yields no values, and keys
+// which are no flag of any command are refused
+func readConfigFile(path string) (map[string]configValue, error) {
+	values := make(map[string]configValue)
+	if path == "" {
+		return values, nil
+	}
+	f, err := os.Open(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return values, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to open config file '%s': %w", path, err)
+	}
+	defer f.Close()
+
+	sc := bufio.NewScanner(f)
+	for n := 1; sc.Scan(); n++ {
+		line := strings.TrimSpace(sc.Text())
+		if line == "" || strings.HasPrefix(line, "#") || line == "---" {
+			continue
+		}
+		key, value, ok := strings.Cut(line, ":")
+		if !ok {
+			return nil, fmt.Errorf("config file '%s', line %d: expecting 'key: value'", path, n)
+		}
+		value = configFileValue(value)
+		// paths relative to the home directory are expanded like the shell does
+		if strings.HasPrefix(value, "~/") {
+			if home, err := os.UserHomeDir(); err == nil {
+				value = filepath.Join(home, value[2:])
+			}
+		}
+		key = strings.TrimSpace(key)
+		if _, ok := configKeys[key]; !ok {
+			return nil, fmt.Errorf("config file '%s', line %d: unknown key '%s'", path, n, key)
+		}
+		values[key] = configValue{Value: value, Line: n}
+	}
+	if err := sc.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read config file '%s': %w", path, err)
+	}
+	return values, nil
+}
+
+// Function that returns a value of the configuration file without the comment following it and without its
+// quotes, YAML double-quoted strings taking Go escapes and single-quoted strings doubling their quotes.
+// A # between the quotes belongs to the value
+func configFileValue(value string) string {
+	value = strings.TrimSpace(value)
+	if end := closingQuote(value); end > 0 {
+		if rest := strings.TrimSpace(value[end+1:]); rest == "" || strings.HasPrefix(rest, "#") {
+			quoted := value[:end+1]
+			if quoted[0] == '\'' {
+				return strings.ReplaceAll(quoted[1:end], "''", "'")
+			}
+			if unquoted, err := strconv.Unquote(quoted); err == nil {
+				return unquoted
+			}
+		}
+	}
+	if i := strings.Index(value, " #"); i >= 0 {
+		value = strings.TrimSpace(value[:i])
+	}
+	return value
+}
+
+// Function that returns the index of the quote closing a value starting with a quote, -1 when there is none
+func closingQuote(value string) int {
+	if value == "" || (value[0] != '"' && value[0] != '\'') {
+		return -1
+	}
+	for i := 1; i < len(value); i++ {
+		switch {
+		case value[0] == '"' && value[i] == '\\':
+			i++
+		case value[0] == '\'' && value[i] == '\'' && i+1 < len(value) && value[i+1] == '\'':
+			i++
+		case value[i] == value[0]:
+			return i
+		}
+	}
+	return -1
+}
+
+// Function that sets the defaults of the flags from the configuration file and the environment,
+// the environment taking precedence; flags given on the command line are parsed afterwards and override both.
+// Only the flags of configKeys take defaults, and the skipped ones keep the defaults of the program
+func applyDefaults(flags *flag.FlagSet, skip ...string) error {
+	path := configFilePath()
+	values, err := readConfigFile(path)
+	if err != nil {
+		return err
+	}
+
+	var setErr error
+	flags.VisitAll(func(f *flag.Flag) {
+		if _, ok := configKeys[f.Name]; !ok || setErr != nil || slices.Contains(skip, f.Name) {
+			return
+		}
+		env := envPrefix + strings.ToUpper(strings.ReplaceAll(f.Name, "-", "_"))
+		if value, ok := os.LookupEnv(env); ok {
+			if err := flags.Set(f.Name, value); err != nil {
+				setErr = fmt.Errorf("invalid value '%s' of %s: %w", value, env, err)
+			}
+		} else if v, ok := values[f.Name]; ok {
+			if err := flags.Set(f.Name, v.Value); err != nil {
+				setErr = fmt.Errorf("config file '%s', line %d: invalid value '%s' of '%s': %w", path, v.Line, v.Value, f.Name, err)
+			}
+		}
+	})
+	return setErr
+}
@@ -0,0 +1,80 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestConfigFileValue(t *testing.T) {
+	tests := []struct {
+		value string
+		want  string
+	}{
+		{"4", "4"},
+		{"4 # comment", "4"},
+		{"eml#1", "eml#1"},
+		{`"~/x"`, "~/x"},
+		{`"~/x" # lab`, "~/x"},
+		{`"~/x"# lab`, "~/x"},
+		{`"a # b"`, "a # b"},
+		{`"a \" b" # c`, `a " b`},
+		{`'it''s' # c`, "it's"},
+		{`'a # b'`, "a # b"},
+		{`"a" b`, `"a" b`},
+		{`"open`, `"open`},
+	}
+	for _, tt := range tests {
+		if got := configFileValue(tt.value); got != tt.want {
+			t.Errorf("configFileValue(%s) = %q, want %q", tt.value, got, tt.want)
+		}
+	}
+}
+
+func TestReadConfigFileRejectsPerRunKeys(t *testing.T) {
+	for _, key := range []string{"i", "o", "n", "dry-run", "f", "force"} {
+		path := filepath.Join(t.TempDir(), "config.yaml")
+		if err := os.WriteFile(path, []byte("format-version: 3\n"+key+": x\n"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		_, err := readConfigFile(path)
+		if err == nil || !strings.Contains(err.Error(), "line 2: unknown key '"+key+"'") {
+			t.Errorf("key %s: got error %v, want unknown key on line 2", key, err)
+		}
+	}
+}
+
+func TestRunDroppedSkipsOutputDefaults(t *testing.T) {
+	dir := t.TempDir()
+	config := filepath.Join(dir, "config.yaml")
+	other := filepath.Join(dir, "other")
+	content := "output-dir: \"" + filepath.ToSlash(other) + "\" # lab\noutput-format: eml\ntemplate: keys\nsha256: true\n"
+	if err := os.WriteFile(config, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv(envPrefix+"CONFIG", config)
+	t.Setenv(envPrefix+"OUTPUT_FORMAT", "bin")
+
+	data, err := os.ReadFile(filepath.Join("pkg", "convert", "testdata", "hf-mf-11223344-dump.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	input := filepath.Join(dir, "card.json")
+	if err := os.WriteFile(input, data, 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := runDropped(input); err != nil {
+		t.Fatal(err)
+	}
+	// the other defaults still apply
+	for _, name := range []string{"card.nfc", "card.nfc.sha256"} {
+		if _, err := os.Stat(filepath.Join(dir, name)); err != nil {
+			t.Errorf("expecting %s next to the input: %v", name, err)
+		}
+	}
+	if _, err := os.Stat(other); err == nil {
+		t.Errorf("output-dir of the config file was used")
+	}
+}
@@ -0,0 +1,140 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/dimchansky/proxmark3-to-flipper/pkg/convert"
+)
+
+// Function that runs the diff mode: compares two dumps of the same card block by block
+func runDiff(args []string) error {
+	var (
+		cfg       config
+		jsonOut   bool
+		colorMode string
+	)
+	fs := flag.NewFlagSet("diff", flag.ExitOnError)
+	fs.BoolVar(&jsonOut, "json", false, "print the differences as JSON")
+	fs.StringVar(&colorMode, "color", "auto", "colorize changed bytes: auto, always or never")
+	fs.BoolVar(&cfg.SwapATQA, "swap-atqa", false, "swap the ATQA bytes of the dumps instead of detecting their order")
+	fs.StringVar(&cfg.InputFormat, "input-format", string(convert.FormatAuto), "input format, detected from the content by default: "+inputFormatList())
+	fs.BoolVar(&cfg.Strict, "strict", false, "reject JSON dumps which are not written the way the official Proxmark3 client writes them")
+	fs.BoolVar(&cfg.Lenient, "lenient", false, "accept JSON dumps of Proxmark3 forks: hex with spaces, missing headers, blocks keyed as \"Block N\"")
+	fs.BoolVar(&cfg.Verbose, "v", false, "log what is parsed and written to standard error")
+	fs.BoolVar(&cfg.Debug, "vv", false, "also log the decisions of the parser: detected format, ATQA byte order, card size, missing blocks")
+	fs.BoolVar(&cfg.Quiet, "quiet", false, "only print errors, no warnings or hints")
+	fs.Usage = func() {
+		_, _ = fmt.Fprintf(fs.Output(), "Usage of %s diff [flags] DUMP_A DUMP_B:\n", os.Args[0])
+		fs.PrintDefaults()
+	}
+	usage = fs.Usage
+	if err := applyDefaults(fs); err != nil {
+		return err
+	}
+	_ = fs.Parse(args)
+	if err := cfg.checkInputFormat(); err != nil {
+		return err
+	}
+
+	if err := cfg.applyLogLevel(); err != nil {
+		return err
+	}
+
+	if fs.NArg() != 2 {
+		return usageError("please provide the two dumps to compare")
+	}
+	color, err := useColor(colorMode, os.Stdout)
+	if err != nil {
+		return err
+	}
+
+	nameA, nameB := fs.Arg(0), fs.Arg(1)
+	a, err := parseMifareFile(nameA, cfg.parseOptions())
+	if err != nil {
+		return err
+	}
+	b, err := parseMifareFile(nameB, cfg.parseOptions())
+	if err != nil {
+		return err
+	}
+
+	d := convert.DiffMifare(a, b)
+	if jsonOut {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(d)
+	}
+	return printDiff(os.Stdout, nameA, nameB, d, color)
+}
+
+// Function that parses a dump which must hold a Mifare Classic card
+func parseMifareFile(fileName string, opts convert.ParseOptions) (*convert.MifareCard, error) {
+	card, err := parseProxMark3File(fileName, opts)
+	if err != nil {
+		return nil, err
+	}
+	mc, ok := card.(*convert.MifareCard)
+	if !ok {
+		return nil, fmt.Errorf("'%s' is not a Mifare Classic dump", fileName)
+	}
+	return mc, nil
+}
+
+// Function that prints the differences block by block, marking the changed bytes
+func printDiff(w io.Writer, nameA, nameB string, d *convert.MifareDiff, color bool) error {
+	var sb strings.Builder
+
+	sb.WriteString(colorize(color, ansiRed, fmt.Sprintf("--- %s\tUID %s, %d blocks", nameA, d.UIDA, d.BlocksA)) + "\n")
+	sb.WriteString(colorize(color, ansiGreen, fmt.Sprintf("+++ %s\tUID %s, %d blocks", nameB, d.UIDB, d.BlocksB)) + "\n")
+
+	sector := -1
+	for _, bd := range d.Blocks {
+		if bd.Sector != sector {
+			sector = bd.Sector
+			sb.WriteString(colorize(color, ansiBold, fmt.Sprintf("Sector %d", sector)) + "\n")
+		}
+		changed := make(map[int]bool, len(bd.Changed))
+		for _, i := range bd.Changed {
+			changed[i] = true
+		}
+
+		sb.WriteString(fmt.Sprintf("  Block %3d - %s\n", bd.Block, diffBytes(bd.A, changed, color, ansiRed)))
+		sb.WriteString(fmt.Sprintf("            + %s\n", diffBytes(bd.B, changed, color, ansiGreen)))
+		if !color {
+			var marks strings.Builder
+			for i := range bd.A.Data {
+				if changed[i] {
+					marks.WriteString("^^ ")
+				} else {
+					marks.WriteString("   ")
+				}
+			}
+			sb.WriteString("              " + strings.TrimRight(marks.String(), " ") + "\n")
+		}
+	}
+
+	if len(d.Blocks) == 0 {
+		sb.WriteString("dumps are identical\n")
+	} else {
+		sb.WriteString(fmt.Sprintf("%d blocks differ in %d sectors\n", len(d.Blocks), d.ChangedSectors()))
+	}
+
+	_, err := io.WriteString(w, sb.String())
+	return err
+}
+
+// Function that prints the bytes of a block, highlighting the changed ones
+func diffBytes(b convert.Block, changed map[int]bool, color bool, ansiColor string) string {
+	parts := strings.Fields(b.String())
+	for i := range parts {
+		if changed[i] {
+			parts[i] = colorize(color, ansiColor, parts[i])
+		}
+	}
+	return strings.Join(parts, " ")
+}
@@ -0,0 +1,87 @@
+package main
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	"github.com/dimchansky/proxmark3-to-flipper/pkg/convert"
+)
+
+// Set when the program was started by dropping a file onto it, to keep its console window open at the end
+var pauseOnExit bool
+
+// Function that reports whether the command line is a single existing file, which is what the desktop
+// passes when a dump is dragged and dropped onto the executable
+func isDroppedFile(args []string) bool {
+	if len(args) != 1 || strings.HasPrefix(args[0], "-") {
+		return false
+	}
+	fi, err := os.Stat(args[0])
+	return err == nil && fi.Mode().IsRegular()
+}
+
+// Flags whose defaults would write the output of a dropped dump elsewhere than next to it as a Flipper file
+var droppedSkippedDefaults = []string{"output-dir", "output-format", "template"}
+
+// Function that runs the drag-and-drop mode: converts the dropped dump with the default settings and
+// writes the output next to it, named after it with the extension matching the card
+func runDropped(fileName string) error {
+	pauseOnExit = runtime.GOOS == "windows"
+
+	// the window must stay open on every error, so flag errors are returned rather than exiting
+	args := []string{"-i", fileName, "-output-dir", filepath.Dir(fileName)}
+	cfg, err := parseConvertArgs(args, flag.ContinueOnError, droppedSkippedDefaults...)
+	if err != nil {
+		return err
+	}
+	cfg.dropped = true
+
+	res := convertFile(cfg)
+	if res.Err != nil {
+		return res.Err
+	}
+	fmt.Printf("%s -> %s\n", res.Input, res.Output)
+	return nil
+}
+
+// Function that names the output of a dropped dump after it, in its directory. There is no command line to
+// pass -f on, so a name already taken is never replaced: -1, -2... is added to the name until it is free.
+// A Flipper file converted again is named with -converted, so it does not replace itself
+func droppedOutputName(cfg *config, c convert.Card) string {
+	ext := cfg.outputExt(c)
+	stem := strings.TrimSuffix(cfg.InputFile, filepath.Ext(cfg.InputFile))
+	if inExt := strings.ToLower(filepath.Ext(cfg.InputFile)); inExt == ".nfc" || inExt == ".rfid" {
+		stem += "-converted"
+	}
+
+	name := stem + ext
+	for n := 1; droppedNameTaken(cfg, name, c); n++ {
+		name = fmt.Sprintf("%s-%d%s", stem, n, ext)
+	}
+	return name
+}
+
+// Function that reports whether an output name or a file written along with it already exists. The key cache
+// in .cache is named after the card whatever the output name, so renaming cannot free it and it is left out
+func droppedNameTaken(cfg *config, name string, c convert.Card) bool {
+	if checkOutputFile(name, false) != nil {
+		return true
+	}
+	for _, f := range companionFiles(cfg, name, c) {
+		if filepath.Dir(f) == filepath.Dir(name) && checkOutputFile(f, false) != nil {
+			return true
+		}
+	}
+	return false
+}
+
+// Function that waits for Enter before the console window of a drag-and-drop run closes
+func waitForEnter() {
+	fmt.Print("Press Enter to close this window...")
+	_, _ = bufio.NewReader(os.Stdin).ReadString('\n')
+}
@@ -0,0 +1,50 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestRunDroppedTwice(t *testing.T) {
+	tests := []struct {
+		name  string
+		dump  string
+		input string
+		want  []string
+	}{
+		{"json dump", "hf-mf-11223344-dump.json", "card.json", []string{"card.nfc", "card-1.nfc", "card-2.nfc"}},
+		{"flipper file", "hf-mf-11223344.nfc", "card.nfc", []string{"card-converted.nfc", "card-converted-1.nfc"}},
+		{"lf key", "em4100.rfid", "key.rfid", []string{"key-converted.rfid", "key-converted-1.rfid"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv(envPrefix+"CONFIG", filepath.Join(t.TempDir(), "config.yaml"))
+			data, err := os.ReadFile(filepath.Join("pkg", "convert", "testdata", tt.dump))
+			if err != nil {
+				t.Fatal(err)
+			}
+			dir := t.TempDir()
+			input := filepath.Join(dir, tt.input)
+			if err := os.WriteFile(input, data, 0o644); err != nil {
+				t.Fatal(err)
+			}
+
+			for _, want := range tt.want {
+				if err := runDropped(input); err != nil {
+					t.Fatalf("dropping %s again: %v", tt.input, err)
+				}
+				if _, err := os.Stat(filepath.Join(dir, want)); err != nil {
+					t.Fatalf("expecting %s to be written: %v", want, err)
+				}
+			}
+			entries, err := os.ReadDir(dir)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(entries) != len(tt.want)+1 {
+				t.Fatalf("got %d files, want the input and %v", len(entries), tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,126 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/dimchansky/proxmark3-to-flipper/pkg/convert"
+)
+
+// Struct representing a value block rewrite requested with -set-value
+type valueEdit struct {
+	Block int
+	Value int32
+}
+
+// List of value block rewrites, filled by repeating -set-value on the command line
+type valueEdits []valueEdit
+
+// String method for valueEdits to satisfy the flag.Value interface
+func (e *valueEdits) String() string {
+	parts := make([]string, len(*e))
+	for i, v := range *e {
+		parts[i] = fmt.Sprintf("%d=%d", v.Block, v.Value)
+	}
+	return strings.Join(parts, ",")
+}
+
+// Set method for valueEdits parsing a BLOCK=VALUE argument
+func (e *valueEdits) Set(s string) error {
+	blockStr, valueStr, ok := strings.Cut(s, "=")
+	if !ok {
+		return fmt.Errorf("expecting BLOCK=VALUE, got '%s'", s)
+	}
+	block, err := strconv.Atoi(strings.TrimSpace(blockStr))
+	if err != nil {
+		return fmt.Errorf("invalid block number '%s'", blockStr)
+	}
+	value, err := strconv.ParseInt(strings.TrimSpace(valueStr), 0, 32)
+	if err != nil {
+		return fmt.Errorf("invalid value '%s', expecting a signed 32-bit number", valueStr)
+	}
+	*e = append(*e, valueEdit{Block: block, Value: int32(value)})
+	return nil
+}
+
+// Struct representing a key replacement requested with -set-key-a or -set-key-b
+type keyEdit struct {
+	Sector int // convert.AllSectors for every sector
+	Key    convert.HexData
+}
+
+// List of key replacements, filled by repeating -set-key-a or -set-key-b on the command line
+type keyEdits []keyEdit
+
+// String method for keyEdits to satisfy the flag.Value interface
+func (e *keyEdits) String() string {
+	parts := make([]string, len(*e))
+	for i, k := range *e {
+		if k.Sector == convert.AllSectors {
+			parts[i] = fmt.Sprintf("%X", []byte(k.Key))
+		} else {
+			parts[i] = fmt.Sprintf("%d:%X", k.Sector, []byte(k.Key))
+		}
+	}
+	return strings.Join(parts, ",")
+}
+
+// Set method for keyEdits parsing a [SECTOR:]KEY argument
+func (e *keyEdits) Set(s string) error {
+	sector := convert.AllSectors
+	keyStr := s
+	if sectorStr, rest, ok := strings.Cut(s, ":"); ok {
+		n, err := strconv.Atoi(strings.TrimSpace(sectorStr))
+		if err != nil || n < 0 {
+			return fmt.Errorf("invalid sector number '%s'", sectorStr)
+		}
+		sector, keyStr = n, rest
+	}
+	key, err := convert.DecodeHexData(strings.TrimSpace(keyStr))
+	if err != nil {
+		return err
+	}
+	if len(key) != convert.ClassicKeySize {
+		return fmt.Errorf("key must be %d bytes, got %d", convert.ClassicKeySize, len(key))
+	}
+	*e = append(*e, keyEdit{Sector: sector, Key: key})
+	return nil
+}
+
+// Function that applies the edits requested on the command line to a parsed card
+func applyEdits(cfg *config, c convert.Card) error {
+	if cfg.SetUID == "" && len(cfg.SetKeysA) == 0 && len(cfg.SetKeysB) == 0 && len(cfg.SetValues) == 0 {
+		return nil
+	}
+	mc, ok := c.(*convert.MifareCard)
+	if !ok {
+		return fmt.Errorf("only Mifare Classic cards can be edited")
+	}
+
+	if cfg.SetUID != "" {
+		uid, err := convert.DecodeHexData(cfg.SetUID)
+		if err != nil {
+			return fmt.Errorf("cannot set UID: %w", err)
+		}
+		if err := mc.SetUID(uid); err != nil {
+			return fmt.Errorf("cannot set UID: %w", err)
+		}
+	}
+	for _, k := range cfg.SetKeysA {
+		if err := mc.SetKeyA(k.Sector, k.Key); err != nil {
+			return fmt.Errorf("cannot set Key A: %w", err)
+		}
+	}
+	for _, k := range cfg.SetKeysB {
+		if err := mc.SetKeyB(k.Sector, k.Key); err != nil {
+			return fmt.Errorf("cannot set Key B: %w", err)
+		}
+	}
+	for _, v := range cfg.SetValues {
+		if err := mc.SetValue(v.Block, v.Value); err != nil {
+			return fmt.Errorf("cannot set value: %w", err)
+		}
+	}
+	return nil
+}
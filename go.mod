@@ -1,3 +1,3 @@
 module github.com/dimchansky/proxmark3-to-flipper
 
-go 1.19
+go 1.21
@@ -0,0 +1,167 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/dimchansky/proxmark3-to-flipper/pkg/convert"
+)
+
+// Struct representing the human-friendly summary of a dump printed by the info mode
+type cardInfo struct {
+	Input         string   `json:"input"`
+	CardType      string   `json:"card_type"`
+	Size          int      `json:"size,omitempty"`
+	Blocks        int      `json:"blocks,omitempty"`
+	UID           string   `json:"uid,omitempty"`
+	Data          string   `json:"data,omitempty"`
+	Manufacturer  string   `json:"manufacturer,omitempty"`
+	ATQA          string   `json:"atqa,omitempty"`
+	SAK           string   `json:"sak,omitempty"`
+	Chip          string   `json:"chip,omitempty"`
+	Sectors       int      `json:"sectors,omitempty"`
+	KeyASectors   int      `json:"key_a_sectors"`
+	KeyBSectors   int      `json:"key_b_sectors"`
+	UnknownBlocks int      `json:"unknown_blocks"`
+	Complete      bool     `json:"complete"`
+	CloneHints    []string `json:"clone_hints,omitempty"`
+}
+
+// Function that builds the summary of a parsed dump
+func newCardInfo(input string, c convert.Card) cardInfo {
+	r := newConversionReport(conversionResult{Card: c})
+	info := cardInfo{
+		Input:         input,
+		CardType:      r.CardType,
+		Size:          r.Size,
+		Blocks:        r.Blocks,
+		UID:           r.UID,
+		Data:          r.Data,
+		UnknownBlocks: r.UnknownBlocks,
+		Complete:      r.UnknownBlocks == 0,
+		CloneHints:    r.CloneHints,
+	}
+
+	switch c := c.(type) {
+	case *convert.MifareCard:
+		info.Manufacturer = convert.UIDManufacturer(c.UID)
+		info.ATQA = c.ATQA.String()
+		info.SAK = c.SAK.String()
+		info.Chip = convert.ChipFromATQASAK(c.ATQA, c.SAK)
+		info.Sectors = convert.ClassicSectorCount(len(c.Blocks))
+		for _, sk := range c.SectorKeys() {
+			if sk.KeyA != nil {
+				info.KeyASectors++
+			}
+			if sk.KeyB != nil {
+				info.KeyBSectors++
+			}
+		}
+	case *convert.BankCard:
+		info.ATQA = c.ATQA.String()
+		info.SAK = c.SAK.String()
+		info.Chip = convert.ChipFromATQASAK(c.ATQA, c.SAK)
+	case *convert.ISO15693Card:
+		info.Manufacturer = convert.UIDManufacturer(c.UID)
+	}
+	return info
+}
+
+// Function that prints the summary of a dump as aligned text
+func printCardInfo(info cardInfo) {
+	line := func(name, format string, args ...interface{}) {
+		fmt.Printf("  %-14s %s\n", name+":", fmt.Sprintf(format, args...))
+	}
+
+	fmt.Printf("%s\n", info.Input)
+	line("Card", "%s", info.CardType)
+	if info.Size > 0 {
+		line("Size", "%d bytes in %d blocks", info.Size, info.Blocks)
+	}
+	if info.UID != "" {
+		line("UID", "%s (%d bytes)", info.UID, len(strings.Fields(info.UID)))
+	}
+	if info.Data != "" {
+		line("Data", "%s", info.Data)
+	}
+	if info.Manufacturer != "" {
+		line("Manufacturer", "%s", info.Manufacturer)
+	}
+	if info.ATQA != "" {
+		line("ATQA/SAK", "%s / %s", info.ATQA, info.SAK)
+		line("Chip", "%s", info.Chip)
+	}
+	if info.Sectors > 0 {
+		line("Keys", "Key A known in %d of %d sectors, Key B in %d", info.KeyASectors, info.Sectors, info.KeyBSectors)
+	}
+	if info.Blocks > 0 {
+		if info.Complete {
+			line("Complete", "yes")
+		} else {
+			line("Complete", "no, %d of %d blocks have unknown bytes", info.UnknownBlocks, info.Blocks)
+		}
+	}
+	for _, h := range info.CloneHints {
+		line("Cloning", "%s", h)
+	}
+}
+
+// Function that runs the info mode: prints a summary of every dump given on the command line
+func runInfo(args []string) error {
+	var (
+		cfg     config
+		jsonOut bool
+	)
+	fs := flag.NewFlagSet("info", flag.ExitOnError)
+	fs.BoolVar(&jsonOut, "json", false, "print the summaries as JSON")
+	fs.BoolVar(&cfg.SwapATQA, "swap-atqa", false, "swap the ATQA bytes of the dumps instead of detecting their order")
+	fs.StringVar(&cfg.InputFormat, "input-format", string(convert.FormatAuto), "input format, detected from the content by default: "+inputFormatList())
+	fs.BoolVar(&cfg.Strict, "strict", false, "reject JSON dumps which are not written the way the official Proxmark3 client writes them")
+	fs.BoolVar(&cfg.Lenient, "lenient", false, "accept JSON dumps of Proxmark3 forks: hex with spaces, missing headers, blocks keyed as \"Block N\"")
+	fs.BoolVar(&cfg.Verbose, "v", false, "log what is parsed and written to standard error")
+	fs.BoolVar(&cfg.Debug, "vv", false, "also log the decisions of the parser: detected format, ATQA byte order, card size, missing blocks")
+	fs.BoolVar(&cfg.Quiet, "quiet", false, "only print errors, no warnings or hints")
+	fs.Usage = func() {
+		_, _ = fmt.Fprintf(fs.Output(), "Usage of %s info [flags] DUMP...:\n", os.Args[0])
+		fs.PrintDefaults()
+	}
+	usage = fs.Usage
+	if err := applyDefaults(fs); err != nil {
+		return err
+	}
+	_ = fs.Parse(args)
+	if err := cfg.checkInputFormat(); err != nil {
+		return err
+	}
+
+	if err := cfg.applyLogLevel(); err != nil {
+		return err
+	}
+
+	if fs.NArg() == 0 {
+		return usageError("please provide the dumps to describe")
+	}
+
+	var infos []cardInfo
+	for i, name := range fs.Args() {
+		card, err := parseProxMark3File(name, cfg.parseOptions())
+		if err != nil {
+			return err
+		}
+		info := newCardInfo(name, card)
+		if jsonOut {
+			infos = append(infos, info)
+			continue
+		}
+		if i > 0 {
+			fmt.Println()
+		}
+		printCardInfo(info)
+	}
+	if jsonOut {
+		return printReport(infos)
+	}
+	return nil
+}
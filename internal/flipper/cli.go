@@ -0,0 +1,142 @@
+// Package flipper talks to a Flipper Zero over the command line interface of its USB serial port
+package flipper
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"time"
+)
+
+// Prompt printed by the Flipper CLI when it waits for a command
+const prompt = ">: "
+
+// Size of the chunks files are uploaded in, small enough for the Flipper to buffer
+const chunkSize = 512
+
+// How long to wait for the Flipper to answer a command
+const commandTimeout = 10 * time.Second
+
+// ErrTimeout is returned when the Flipper does not answer in time
+var ErrTimeout = errors.New("timed out waiting for the Flipper CLI")
+
+// Struct representing a session with the Flipper CLI
+type CLI struct {
+	port io.ReadWriter
+	buf  []byte
+}
+
+// Function that starts a CLI session on an opened serial port, whose reads must return after a
+// timeout without data, and waits for the first prompt
+func NewCLI(port io.ReadWriter) (*CLI, error) {
+	c := &CLI{port: port}
+	if _, err := port.Write([]byte("\r")); err != nil {
+		return nil, fmt.Errorf("failed to write to the Flipper: %w", err)
+	}
+	if _, err := c.readUntil(prompt); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// Function that runs a command and returns what it printed before the next prompt, without the echo
+func (c *CLI) Run(command string) (string, error) {
+	if _, err := c.port.Write([]byte(command + "\r")); err != nil {
+		return "", fmt.Errorf("failed to write to the Flipper: %w", err)
+	}
+	out, err := c.readUntil(prompt)
+	if err != nil {
+		return "", err
+	}
+	out = bytes.TrimPrefix(out, []byte(command))
+	return string(bytes.TrimSpace(out)), nil
+}
+
+// Function that writes a file to the Flipper storage, e.g. /ext/nfc/card.nfc, creating its directory
+// and replacing an existing file
+func (c *CLI) WriteFile(fileName string, data []byte) error {
+	quoted, err := quotePath(fileName)
+	if err != nil {
+		return err
+	}
+	quotedDir, err := quotePath(path.Dir(fileName))
+	if err != nil {
+		return err
+	}
+
+	// both fail harmlessly when the directory exists or the file does not
+	if _, err := c.Run("storage mkdir " + quotedDir); err != nil {
+		return err
+	}
+	if _, err := c.Run("storage remove " + quoted); err != nil {
+		return err
+	}
+
+	// write_chunk appends to the file, so upload it piece by piece
+	for len(data) > 0 {
+		n := len(data)
+		if n > chunkSize {
+			n = chunkSize
+		}
+		if _, err := c.port.Write([]byte(fmt.Sprintf("storage write_chunk %s %d\r", quoted, n))); err != nil {
+			return fmt.Errorf("failed to write to the Flipper: %w", err)
+		}
+		out, err := c.readUntil("Ready", prompt)
+		if err != nil {
+			return err
+		}
+		if !bytes.HasSuffix(out, []byte("Ready")) {
+			return fmt.Errorf("Flipper refused to write '%s': %s", fileName, bytes.TrimSpace(out))
+		}
+		if _, err := c.port.Write(data[:n]); err != nil {
+			return fmt.Errorf("failed to write to the Flipper: %w", err)
+		}
+		if _, err := c.readUntil(prompt); err != nil {
+			return err
+		}
+		data = data[n:]
+	}
+	return nil
+}
+
+// Function that quotes a path for the Flipper CLI so that spaces do not split it into several arguments.
+// The CLI has no escapes, so paths with a double quote or a control character are refused
+func quotePath(name string) (string, error) {
+	for _, r := range name {
+		if r == '"' || r < 0x20 || r == 0x7F {
+			return "", fmt.Errorf("cannot upload %q to the Flipper: file names cannot contain double quotes or control characters", name)
+		}
+	}
+	return `"` + name + `"`, nil
+}
+
+// Function that reads from the port until one of the tokens shows up and returns everything read up
+// to the end of that token, keeping what follows it for the next read
+func (c *CLI) readUntil(tokens ...string) ([]byte, error) {
+	deadline := time.Now().Add(commandTimeout)
+	chunk := make([]byte, 256)
+	for {
+		for _, token := range tokens {
+			if i := bytes.Index(c.buf, []byte(token)); i >= 0 {
+				end := i + len(token)
+				out := append([]byte(nil), c.buf[:end]...)
+				c.buf = c.buf[end:]
+				if token == prompt {
+					out = out[:len(out)-len(prompt)]
+				}
+				return out, nil
+			}
+		}
+		if time.Now().After(deadline) {
+			return nil, ErrTimeout
+		}
+
+		n, err := c.port.Read(chunk)
+		c.buf = append(c.buf, chunk[:n]...)
+		if err != nil && err != io.EOF {
+			return nil, fmt.Errorf("failed to read from the Flipper: %w", err)
+		}
+	}
+}
@@ -0,0 +1,23 @@
+package flipper
+
+import "testing"
+
+func TestQuotePath(t *testing.T) {
+	tests := []struct {
+		name string
+		want string
+		ok   bool
+	}{
+		{"/ext/nfc/card.nfc", `"/ext/nfc/card.nfc"`, true},
+		{"/ext/nfc/office badge.nfc", `"/ext/nfc/office badge.nfc"`, true},
+		{`/ext/nfc/a".nfc`, "", false},
+		{"/ext/nfc/a\r\nstorage remove /ext.nfc", "", false},
+		{"/ext/nfc/a\x7f.nfc", "", false},
+	}
+	for _, tt := range tests {
+		got, err := quotePath(tt.name)
+		if (err == nil) != tt.ok || got != tt.want {
+			t.Errorf("quotePath(%q) = %q, %v, want %q", tt.name, got, err, tt.want)
+		}
+	}
+}
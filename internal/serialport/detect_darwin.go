@@ -0,0 +1,21 @@
+package serialport
+
+import (
+	"path/filepath"
+	"sort"
+)
+
+// Function that finds the ports of the device by the names macOS gives them
+func detect(dev Device) ([]string, error) {
+	for _, pattern := range dev.MacPatterns {
+		ports, err := filepath.Glob(pattern)
+		if err != nil {
+			return nil, err
+		}
+		if len(ports) > 0 {
+			sort.Strings(ports)
+			return ports, nil
+		}
+	}
+	return nil, nil
+}
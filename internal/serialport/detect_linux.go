@@ -0,0 +1,36 @@
+package serialport
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// Function that finds the ports of the device by the USB IDs sysfs reports for every ACM tty
+func detect(dev Device) ([]string, error) {
+	ttys, err := filepath.Glob("/sys/class/tty/ttyACM*")
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(ttys)
+
+	var ports []string
+	for _, tty := range ttys {
+		// the tty device is an interface of the USB device holding the IDs
+		usbDev := filepath.Join(tty, "device", "..")
+		if readID(filepath.Join(usbDev, "idVendor")) == dev.VID && readID(filepath.Join(usbDev, "idProduct")) == dev.PID {
+			ports = append(ports, "/dev/"+filepath.Base(tty))
+		}
+	}
+	return ports, nil
+}
+
+// Function that reads a USB ID from a sysfs attribute file
+func readID(fileName string) string {
+	bs, err := os.ReadFile(fileName)
+	if err != nil {
+		return ""
+	}
+	return strings.ToLower(strings.TrimSpace(string(bs)))
+}
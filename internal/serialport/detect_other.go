@@ -0,0 +1,10 @@
+//go:build !linux && !darwin && !windows
+
+package serialport
+
+import "errors"
+
+// Function that reports that port detection is not available on this platform
+func detect(dev Device) ([]string, error) {
+	return nil, errors.New("serial port detection is not supported on this platform, please provide the port")
+}
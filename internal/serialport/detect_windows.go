@@ -0,0 +1,27 @@
+package serialport
+
+import (
+	"os/exec"
+	"regexp"
+	"strings"
+)
+
+// Regular expression matching the COM port names in the output of reg.exe
+var portNameRe = regexp.MustCompile(`PortName\s+REG_SZ\s+(COM\d+)`)
+
+// Function that finds the COM ports Windows assigned to the device in the USB enumeration registry,
+// which also remembers devices connected in the past
+func detect(dev Device) ([]string, error) {
+	key := `HKLM\SYSTEM\CurrentControlSet\Enum\USB\VID_` + strings.ToUpper(dev.VID) + `&PID_` + strings.ToUpper(dev.PID)
+	out, err := exec.Command("reg", "query", key, "/s", "/v", "PortName").Output()
+	if err != nil {
+		// reg.exe fails when the device was never connected
+		return nil, nil
+	}
+
+	var ports []string
+	for _, m := range portNameRe.FindAllStringSubmatch(string(out), -1) {
+		ports = append(ports, m[1])
+	}
+	return ports, nil
+}
@@ -0,0 +1,13 @@
+//go:build !linux && !darwin && !windows
+
+package serialport
+
+import (
+	"errors"
+	"os"
+)
+
+// Function that reports that serial ports are not supported on this platform
+func Open(port string) (*os.File, error) {
+	return nil, errors.New("serial ports are not supported on this platform")
+}
@@ -0,0 +1,47 @@
+//go:build linux || darwin
+
+package serialport
+
+import (
+	"fmt"
+	"os"
+	"syscall"
+	"unsafe"
+)
+
+// Function that opens the serial port in raw mode, with reads returning after ReadTimeout without data
+func Open(port string) (*os.File, error) {
+	f, err := os.OpenFile(port, os.O_RDWR|syscall.O_NOCTTY, 0)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open serial port '%s': %w", port, err)
+	}
+
+	var t syscall.Termios
+	if err := ioctl(f.Fd(), ioctlGetTermios, uintptr(unsafe.Pointer(&t))); err != nil {
+		_ = f.Close()
+		return nil, fmt.Errorf("failed to get serial port '%s' attributes: %w", port, err)
+	}
+
+	// the same settings as cfmakeraw, the baud rate does not matter for USB CDC devices
+	t.Iflag &^= syscall.IGNBRK | syscall.BRKINT | syscall.PARMRK | syscall.ISTRIP | syscall.INLCR | syscall.IGNCR | syscall.ICRNL | syscall.IXON
+	t.Oflag &^= syscall.OPOST
+	t.Lflag &^= syscall.ECHO | syscall.ECHONL | syscall.ICANON | syscall.ISIG | syscall.IEXTEN
+	t.Cflag &^= syscall.CSIZE | syscall.PARENB
+	t.Cflag |= syscall.CS8 | syscall.CREAD | syscall.CLOCAL
+	t.Cc[syscall.VMIN] = 0
+	t.Cc[syscall.VTIME] = uint8(ReadTimeout.Milliseconds() / 100)
+
+	if err := ioctl(f.Fd(), ioctlSetTermios, uintptr(unsafe.Pointer(&t))); err != nil {
+		_ = f.Close()
+		return nil, fmt.Errorf("failed to set serial port '%s' attributes: %w", port, err)
+	}
+	return f, nil
+}
+
+// Function that runs an ioctl request on a file descriptor
+func ioctl(fd, req, arg uintptr) error {
+	if _, _, errno := syscall.Syscall(syscall.SYS_IOCTL, fd, req, arg); errno != 0 {
+		return errno
+	}
+	return nil
+}
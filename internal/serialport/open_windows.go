@@ -0,0 +1,38 @@
+package serialport
+
+import (
+	"fmt"
+	"os"
+	"syscall"
+	"unsafe"
+)
+
+// Struct mirroring the COMMTIMEOUTS structure of the Windows API
+type commTimeouts struct {
+	ReadIntervalTimeout         uint32
+	ReadTotalTimeoutMultiplier  uint32
+	ReadTotalTimeoutConstant    uint32
+	WriteTotalTimeoutMultiplier uint32
+	WriteTotalTimeoutConstant   uint32
+}
+
+// SetCommTimeouts from kernel32.dll, used to make reads return after ReadTimeout without data
+var procSetCommTimeouts = syscall.NewLazyDLL("kernel32.dll").NewProc("SetCommTimeouts")
+
+// Function that opens the COM port, with reads returning after ReadTimeout without data
+func Open(port string) (*os.File, error) {
+	f, err := os.OpenFile(`\\.\`+port, os.O_RDWR, 0)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open serial port '%s': %w", port, err)
+	}
+
+	t := commTimeouts{
+		ReadIntervalTimeout:      ^uint32(0),
+		ReadTotalTimeoutConstant: uint32(ReadTimeout.Milliseconds()),
+	}
+	if r, _, err := procSetCommTimeouts.Call(f.Fd(), uintptr(unsafe.Pointer(&t))); r == 0 {
+		_ = f.Close()
+		return nil, fmt.Errorf("failed to set serial port '%s' timeouts: %w", port, err)
+	}
+	return f, nil
+}
@@ -0,0 +1,48 @@
+// Package serialport finds and opens the USB CDC serial ports of the devices the converter talks to
+package serialport
+
+import (
+	"errors"
+	"time"
+)
+
+// How long a read from an opened port waits for data before returning nothing
+const ReadTimeout = time.Second
+
+// Struct describing a USB serial device by its USB IDs and the names macOS gives its port
+type Device struct {
+	Name        string
+	VID, PID    string   // lowercase hex USB vendor and product IDs
+	MacPatterns []string // glob patterns of the macOS device files, most specific first
+}
+
+// Devices the converter knows how to find
+var (
+	Proxmark3 = Device{
+		Name:        "Proxmark3",
+		VID:         "9ac4",
+		PID:         "4b8f",
+		MacPatterns: []string{"/dev/tty.usbmodemiceman*", "/dev/tty.usbmodem*"},
+	}
+	Flipper = Device{
+		Name:        "Flipper Zero",
+		VID:         "0483",
+		PID:         "5740",
+		MacPatterns: []string{"/dev/cu.usbmodemflip*"},
+	}
+)
+
+// ErrNotFound is returned when no port of the device is connected
+var ErrNotFound = errors.New("device not found")
+
+// Function that returns the serial port of the first connected device, see detect for the per-platform lookup
+func Detect(dev Device) (string, error) {
+	ports, err := detect(dev)
+	if err != nil {
+		return "", err
+	}
+	if len(ports) == 0 {
+		return "", ErrNotFound
+	}
+	return ports[0], nil
+}
@@ -0,0 +1,9 @@
+package serialport
+
+import "syscall"
+
+// ioctl requests reading and writing the terminal attributes
+const (
+	ioctlGetTermios = syscall.TIOCGETA
+	ioctlSetTermios = syscall.TIOCSETA
+)
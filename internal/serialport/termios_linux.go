@@ -0,0 +1,9 @@
+package serialport
+
+import "syscall"
+
+// ioctl requests reading and writing the terminal attributes
+const (
+	ioctlGetTermios = syscall.TCGETS
+	ioctlSetTermios = syscall.TCSETS
+)
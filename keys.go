@@ -0,0 +1,101 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/dimchansky/proxmark3-to-flipper/pkg/convert"
+)
+
+// Function that returns the key dictionary format to write: the one selected on the command line,
+// or the one matching the extension of the keys file
+func keysDictFormat(cfg *config) convert.DictFormat {
+	if cfg.KeysFormat != "" {
+		return convert.DictFormat(cfg.KeysFormat)
+	}
+	if strings.EqualFold(filepath.Ext(cfg.KeysFile), ".nfc") {
+		return convert.DictFlipper
+	}
+	return convert.DictProxmark3
+}
+
+// Function that returns the unique keys found in the sector trailers of a card, nil for cards without keys
+func cardKeys(c convert.Card) []convert.HexData {
+	if mc, ok := c.(*convert.MifareCard); ok {
+		return mc.UniqueKeys()
+	}
+	return nil
+}
+
+// Function that creates a key dictionary file, or uses standard output for "-", and writes the keys to it
+func writeKeysFile(cfg *config, keys []convert.HexData) error {
+	format := keysDictFormat(cfg)
+	if cfg.KeysFile == stdioFileName {
+		return convert.WriteKeysDict(os.Stdout, keys, format)
+	}
+
+	err := writeFileAtomic(cfg.KeysFile, "keys file", cfg.Force, func(w io.Writer) error {
+		return convert.WriteKeysDict(w, keys, format)
+	})
+	if err != nil {
+		return err
+	}
+	_, _ = fmt.Fprintf(os.Stderr, "wrote %d unique keys to %s\n", len(keys), cfg.KeysFile)
+	return nil
+}
+
+// Function that runs the keys mode: writes the unique sector keys of one or more dumps into a key dictionary
+func runKeys(args []string) error {
+	cfg := config{KeysFile: stdioFileName}
+	fs := flag.NewFlagSet("keys", flag.ExitOnError)
+	fs.StringVar(&cfg.KeysFile, "o", stdioFileName, "key dictionary file to write, '-' for stdout")
+	fs.StringVar(&cfg.KeysFormat, "keys-format", "", "key dictionary format: pm3 (.dic) or flipper (mf_classic_dict_user.nfc), by default chosen by the file extension")
+	fs.BoolVar(&cfg.SwapATQA, "swap-atqa", false, "swap the ATQA bytes of the dumps instead of detecting their order")
+	fs.StringVar(&cfg.InputFormat, "input-format", string(convert.FormatAuto), "input format, detected from the content by default: "+inputFormatList())
+	fs.BoolVar(&cfg.Strict, "strict", false, "reject JSON dumps which are not written the way the official Proxmark3 client writes them")
+	fs.BoolVar(&cfg.Lenient, "lenient", false, "accept JSON dumps of Proxmark3 forks: hex with spaces, missing headers, blocks keyed as \"Block N\"")
+	fs.BoolVar(&cfg.Verbose, "v", false, "log what is parsed and written to standard error")
+	fs.BoolVar(&cfg.Debug, "vv", false, "also log the decisions of the parser: detected format, ATQA byte order, card size, missing blocks")
+	fs.BoolVar(&cfg.Quiet, "quiet", false, "only print errors, no warnings or hints")
+	fs.BoolVar(&cfg.Force, "f", false, "overwrite existing output files, which are left untouched otherwise")
+	fs.BoolVar(&cfg.Force, "force", false, "same as -f")
+	fs.Usage = func() {
+		_, _ = fmt.Fprintf(fs.Output(), "Usage of %s keys [flags] DUMP...:\n", os.Args[0])
+		fs.PrintDefaults()
+	}
+	usage = fs.Usage
+	if err := applyDefaults(fs); err != nil {
+		return err
+	}
+	_ = fs.Parse(args)
+	if err := cfg.checkInputFormat(); err != nil {
+		return err
+	}
+
+	if err := cfg.applyLogLevel(); err != nil {
+		return err
+	}
+
+	if fs.NArg() == 0 {
+		return usageError("please provide the dumps to extract the keys from")
+	}
+	switch convert.DictFormat(cfg.KeysFormat) {
+	case "", convert.DictProxmark3, convert.DictFlipper:
+	default:
+		return usageError(fmt.Sprintf("unsupported key dictionary format '%s', expecting pm3 or flipper", cfg.KeysFormat))
+	}
+
+	var keys []convert.HexData
+	for _, name := range fs.Args() {
+		card, err := parseProxMark3File(name, cfg.parseOptions())
+		if err != nil {
+			return err
+		}
+		keys = convert.AppendUniqueKeys(keys, cardKeys(card)...)
+	}
+	return writeKeysFile(&cfg, keys)
+}
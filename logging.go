@@ -0,0 +1,46 @@
+package main
+
+import (
+	"context"
+	"log/slog"
+	"os"
+)
+
+// Level of the messages logged to standard error: warnings by default, raised by -quiet and lowered by -v and -vv
+var logLevel = new(slog.LevelVar)
+
+// Logger of the program, writing key=value lines to standard error
+var logger = slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: logLevel, ReplaceAttr: withoutTime}))
+
+func init() {
+	logLevel.Set(slog.LevelWarn)
+}
+
+// Function that drops the time from log lines, which only clutters the output of a command line tool
+func withoutTime(groups []string, a slog.Attr) slog.Attr {
+	if len(groups) == 0 && a.Key == slog.TimeKey {
+		return slog.Attr{}
+	}
+	return a
+}
+
+// Function that sets the log level selected on the command line
+func (c *config) applyLogLevel() error {
+	if c.Quiet && (c.Verbose || c.Debug) {
+		return usageError("-quiet cannot be used together with -v or -vv")
+	}
+	switch {
+	case c.Quiet:
+		logLevel.Set(slog.LevelError)
+	case c.Debug:
+		logLevel.Set(slog.LevelDebug)
+	case c.Verbose:
+		logLevel.Set(slog.LevelInfo)
+	}
+	return nil
+}
+
+// Function that reports whether warnings are printed, which -quiet turns off
+func warningsEnabled() bool {
+	return logger.Enabled(context.Background(), slog.LevelWarn)
+}
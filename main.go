@@ -1,32 +1,40 @@
 package main
 
 import (
-	"encoding/hex"
-	"encoding/json"
 	"errors"
 	"flag"
 	"fmt"
 	"io"
 	"os"
-	"strconv"
+	"path/filepath"
 	"strings"
+	"text/template"
+
+	"github.com/dimchansky/proxmark3-to-flipper/pkg/convert"
 )
 
 var (
 	// Program metadata set by the compiler
-	Version = "undefined"   // Program's version
+	Version   = "undefined" // Program's version
 	BuildTime = "undefined" // Build time of the program
-	GitHash = "undefined"   // Git commit hash of the source tree
+	GitHash   = "undefined" // Git commit hash of the source tree
 )
 
 // Entry point of the program
 func main() {
-	if err := run(); err != nil {  
+	usage = printUsage
+	err := run()
+	if err != nil {
 		_, _ = fmt.Fprintf(os.Stderr, "error: %v\n", err)
 		var usageErr usageError
 		if errors.As(err, &usageErr) {
-			flag.Usage()
+			usage()
 		}
+	}
+	if pauseOnExit {
+		waitForEnter()
+	}
+	if err != nil {
 		os.Exit(1)
 	}
 }
@@ -39,198 +47,304 @@ func (u usageError) Error() string {
 	return string(u)
 }
 
-// The run function orchestrates the entire workflow of the program
-func run() error {
-	cfg, err := parseArgs()  
-	if err != nil {
-		return err
-	}
+// Prints the help of the mode being run, replaced by the modes with their own flags
+var usage func()
 
-	card, err := parseProxMark3JSONFile(cfg.InputJSONFile)  
-	if err != nil {
-		return err
-	}
+// Struct describing a command of the program
+type command struct {
+	Name    string
+	Summary string
+	Run     func(args []string) error
+}
 
-	return writeNFCFile(cfg.OutputNFCFile, card)  
+// Commands of the program, in the order they are listed in the help
+var commands = []command{
+	{"convert", "convert dumps into Flipper files (the default when only flags are given)", runConvert},
+	{"info", "print a summary of dumps: card type, UID, chip, known keys, completeness", runInfo},
+	{"validate", "check a dump for structural correctness", runValidate},
+	{"keys", "extract the sector keys of dumps into a key dictionary", runKeys},
+	{"diff", "compare two dumps of the same card block by block", runDiff},
+	{"merge", "combine partial dumps of the same card", runMerge},
+	{"read", "dump a card through the Proxmark3 client and convert it", runRead},
+	{"ndef", "decode the NDEF records of a dump", runNDEF},
+	{"tui", "browse, preview and convert a folder of dumps interactively", runTUI},
+	{"serve", "run an HTTP service converting and validating the dumps posted to it", runServe},
 }
 
-// Struct that holds names of input and output files
-type config struct {
-	InputJSONFile string
-	OutputNFCFile string
+// Function that prints the commands of the program
+func printUsage() {
+	out := flag.CommandLine.Output()
+	_, _ = fmt.Fprintf(out, "Usage: %s COMMAND [flags]\n\nCommands:\n", os.Args[0])
+	for _, c := range commands {
+		_, _ = fmt.Fprintf(out, "  %-10s %s\n", c.Name, c.Summary)
+	}
+	_, _ = fmt.Fprintf(out, "\nRun '%s help COMMAND' for the flags of a command; '%s -i DUMP -o FILE' still converts.\n", os.Args[0], os.Args[0])
+	_, _ = fmt.Fprintf(out, "'%s DUMP' converts the dump next to it, which is what dropping a file onto the program does.\n", os.Args[0])
+	_, _ = fmt.Fprintf(out, "Version: %s\tBuildTime: %v\tGitHash: %s\n", Version, BuildTime, GitHash)
 }
 
-// Function to parse command line arguments and return a config struct
-func parseArgs() (*config, error) {
-	var cfg config
-	flag.StringVar(&cfg.InputJSONFile, "i", "", "input Proxmark3 dump file in JSON format")
-	flag.StringVar(&cfg.OutputNFCFile, "o", "", "output Flipper file in NFC format")
+// The run function dispatches the command line to the command it names
+func run() error {
+	args := os.Args[1:]
+	if len(args) == 0 {
+		return usageError("please provide a command")
+	}
 
-	defaultUsage := flag.Usage
-	flag.Usage = func() {
-		defaultUsage()
-		_, _ = fmt.Fprintf(flag.CommandLine.Output(), "Version: %s\tBuildTime: %v\tGitHash: %s\n", Version, BuildTime, GitHash)
+	switch args[0] {
+	case "help", "-h", "-help", "--help":
+		if len(args) > 1 {
+			return runCommand(args[1], []string{"-h"})
+		}
+		printUsage()
+		return nil
+	case "version", "-version", "--version":
+		fmt.Printf("Version: %s\tBuildTime: %v\tGitHash: %s\n", Version, BuildTime, GitHash)
+		return nil
 	}
-	flag.Parse()  
 
-	if cfg.InputJSONFile == "" {
-		return nil, usageError("please provide input Proxmark3 dump file in JSON format")
+	// flags without a command keep working as the original -i/-o converter
+	if strings.HasPrefix(args[0], "-") {
+		return runConvert(args)
 	}
+	if !isCommand(args[0]) && isDroppedFile(args) {
+		return runDropped(args[0])
+	}
+	return runCommand(args[0], args[1:])
+}
 
-	if cfg.OutputNFCFile == "" {
-		return nil, usageError("please provide output Flipper file in NFC format")
+// Function that reports whether name is one of the commands of the program
+func isCommand(name string) bool {
+	for _, c := range commands {
+		if c.Name == name {
+			return true
+		}
 	}
+	return false
+}
 
-	return &cfg, nil
+// Function that runs the named command with its arguments
+func runCommand(name string, args []string) error {
+	for _, c := range commands {
+		if c.Name == name {
+			return c.Run(args)
+		}
+	}
+	return usageError(fmt.Sprintf("unknown command '%s'", name))
 }
 
-// Type for a slice of bytes, which is used to represent hexadecimal data
-type hexData []byte
+// Name used on the command line for standard input and output
+const stdioFileName = "-"
 
-// String method for hexData type to print hexadecimal data
-func (h hexData) String() string {
-	var sb strings.Builder
+// Struct that holds names of input and output files and the conversion settings
+type config struct {
+	InputFile      string
+	OutputFile     string
+	OutputDir      string
+	FormatVersion  int
+	SwapATQA       bool
+	InputFormat    string
+	Strict         bool
+	Lenient        bool
+	OutputFormat   string
+	KeysFile       string
+	KeysFormat     string
+	FlipperUpload  bool
+	FlipperPort    string
+	EmulationCache bool
+	Report         string
+	Jobs           int
+	AnnotateAccess bool
+	SetUID         string
+	SetKeysA       keyEdits
+	SetKeysB       keyEdits
+	SetValues      valueEdits
+	T5577File      string
+	T5577Format    string
+	Provenance     bool
+	Checksum       bool
+	Template       string
+	Verbose        bool
+	Debug          bool
+	Quiet          bool
+	DryRun         bool
+	Force          bool
+	template       *template.Template // loaded -template
+	templateExt    string             // extension of the files rendered by the template
+	dropped        bool               // converting a file dropped onto the executable
+}
+
+// Function that returns the parser options selected on the command line
+func (c *config) parseOptions() convert.ParseOptions {
+	opts := convert.ParseOptions{SwapATQA: c.SwapATQA, Format: convert.InputFormat(c.InputFormat), Logger: logger}
+	switch {
+	case c.Strict:
+		opts.Mode = convert.ParseStrict
+	case c.Lenient:
+		opts.Mode = convert.ParseLenient
+	}
+	return opts
+}
 
-	n := len(h)
-	for i := 0; i < n-1; i++ {
-		sb.WriteString(fmt.Sprintf("%02X ", h[i]))
+// Function that checks the input format and parse mode selected on the command line
+func (c *config) checkInputFormat() error {
+	if c.Strict && c.Lenient {
+		return usageError("-strict and -lenient cannot be used together")
 	}
-	if n >= 1 {
-		sb.WriteString(fmt.Sprintf("%02X", h[n-1]))
+	for _, f := range convert.InputFormats {
+		if convert.InputFormat(c.InputFormat) == f {
+			return nil
+		}
 	}
+	return usageError(fmt.Sprintf("unsupported input format '%s', expecting one of %s", c.InputFormat, inputFormatList()))
+}
 
-	return sb.String()
+// Function that lists the input formats for the help text
+func inputFormatList() string {
+	names := make([]string, len(convert.InputFormats))
+	for i, f := range convert.InputFormats {
+		names[i] = string(f)
+	}
+	return strings.Join(names, ", ")
 }
 
-// Struct representing the data structure of a Mifare card
-type mifareCard struct {
-	UID    hexData
-	ATQA   hexData
-	SAK    hexData
-	Blocks []hexData
+// Function that returns the writer options selected on the command line
+func (c *config) writeOptions() convert.WriteOptions {
+	return convert.WriteOptions{NFCVersion: c.FormatVersion, Format: c.outputFormat(), AnnotateAccess: c.AnnotateAccess}
 }
 
-// Function that reads a Proxmark3 JSON dump file and returns a mifareCard struct
-func parseProxMark3JSONFile(fileName string) (*mifareCard, error) {
-	jsonFile, err := os.Open(fileName)
-	if err != nil {
-		return nil, fmt.Errorf("failed to parse Proxmark3 dump file '%s': %w", fileName, err)
+// Function that returns the output format selected on the command line, or the one matching
+// the extension of the output file
+func (c *config) outputFormat() convert.OutputFormat {
+	if c.OutputFormat != "" {
+		return convert.OutputFormat(c.OutputFormat)
 	}
-	defer jsonFile.Close()
-
-	return parseProxMark3JSON(jsonFile)
+	ext := strings.ToLower(strings.TrimPrefix(filepath.Ext(c.OutputFile), "."))
+	for _, f := range convert.OutputFormats {
+		if ext == string(f) {
+			return f
+		}
+	}
+	return convert.OutputFlipper
 }
 
-// Function that parses the Proxmark3 JSON data and returns a mifareCard struct
-func parseProxMark3JSON(r io.Reader) (*mifareCard, error) {
-	var proxmark3JSON struct {
-		Created  string `json:"Created"`
-		FileType string `json:"FileType"`
-		Card     struct {
-			UID  string `json:"UID"`
-			ATQA string `json:"ATQA"`
-			SAK  string `json:"SAK"`
-		} `json:"Card"`
-		Blocks map[string]string `json:"blocks"`
+// Function that returns the extension of the output files of a card: the one of the -template, or of
+// the selected output format
+func (c *config) outputExt(card convert.Card) string {
+	if c.template != nil {
+		return c.templateExt
 	}
+	return convert.OutputExt(card, c.outputFormat())
+}
 
-	if err := json.NewDecoder(r).Decode(&proxmark3JSON); err != nil {
-		return nil, fmt.Errorf("failed to decode Proxmark3 JSON file: %w", err)
+// Function that checks the output format selected on the command line
+func (c *config) checkOutputFormat() error {
+	if c.OutputFormat == "" {
+		return nil
+	}
+	for _, f := range convert.OutputFormats {
+		if convert.OutputFormat(c.OutputFormat) == f {
+			return nil
+		}
 	}
+	return usageError(fmt.Sprintf("unsupported output format '%s', expecting one of %s", c.OutputFormat, outputFormatList()))
+}
 
-	if proxmark3JSON.Created != "proxmark3" {
-		return nil, errors.New("JSON file must be produced by Proxmark3")
+// Function that lists the output formats for the help text
+func outputFormatList() string {
+	names := make([]string, len(convert.OutputFormats))
+	for i, f := range convert.OutputFormats {
+		names[i] = string(f)
 	}
+	return strings.Join(names, ", ")
+}
 
-	if proxmark3JSON.FileType != "mfcard" {
-		return nil, errors.New("expecting Mifare card dump")
+// Function that returns the input dump of a command taking a single one, given as its argument like the dumps
+// of info, or with -i. The argument takes precedence over an -i set in the config file
+func inputArg(fs *flag.FlagSet, inputFlag string) (string, error) {
+	switch fs.NArg() {
+	case 0:
+		return inputFlag, nil
+	case 1:
+		return fs.Arg(0), nil
 	}
+	return "", usageError(fmt.Sprintf("please provide a single input dump, got %d", fs.NArg()))
+}
 
-	card := &proxmark3JSON.Card
-	uid, err := decodeHexData(card.UID)
-	if err != nil {
-		return nil, fmt.Errorf("cannot parse card UID: %w", err)
+// Function that reads a Proxmark3 dump file, or standard input for "-", and returns the card it describes
+func parseProxMark3File(fileName string, opts convert.ParseOptions) (convert.Card, error) {
+	if opts.Logger != nil {
+		opts.Logger = opts.Logger.With("file", fileName)
 	}
-	atqa, err := decodeHexData(card.ATQA)
-	if err != nil {
-		return nil, fmt.Errorf("cannot parse card ATQA: %w", err)
+	if fileName == stdioFileName {
+		return convert.ParseWithOptions(os.Stdin, opts)
 	}
-	sak, err := decodeHexData(card.SAK)
+
+	dumpFile, err := os.Open(fileName)
 	if err != nil {
-		return nil, fmt.Errorf("cannot parse card SAK: %w", err)
+		return nil, fmt.Errorf("failed to parse Proxmark3 dump file '%s': %w", fileName, err)
+	}
+	defer dumpFile.Close()
+
+	return convert.ParseWithOptions(dumpFile, opts)
+}
+
+// Function that prints the parser warnings, the cloning hints and a summary of the blocks of a partial dump which are written as unknown data
+func reportCard(fileName string, c convert.Card, opts convert.WriteOptions) {
+	r := newConversionReport(conversionResult{Input: fileName, Card: c})
+	logger.Info("parsed dump", "file", fileName, "card", r.CardType, "uid", r.UID, "blocks", r.Blocks, "unknown_blocks", r.UnknownBlocks)
+	if !warningsEnabled() {
+		return
 	}
 
-	blocksMap := proxmark3JSON.Blocks
-	blocksNum := len(blocksMap)
-	blocks := make([]hexData, blocksNum)
-	for i := 0; i < blocksNum; i++ {
-		blockNumStr := strconv.Itoa(i)
-		blockData, ok := blocksMap[blockNumStr]
-		if !ok {
-			return nil, fmt.Errorf("cannot find Mifare card data for block %d", i)
+	if bc, ok := c.(*convert.BankCard); ok {
+		color, _ := useColor("auto", os.Stderr)
+		for _, w := range bc.Warnings {
+			_, _ = fmt.Fprintf(os.Stderr, "%s\n", colorize(color, ansiRed, fmt.Sprintf("warning: %s: %s", fileName, w)))
 		}
-		bs, err := decodeHexData(blockData)
-		if err != nil {
-			return nil, fmt.Errorf("cannot parse block %d data: %w", i, err)
+		return
+	}
+	mc, ok := c.(*convert.MifareCard)
+	if !ok {
+		return
+	}
+	reportWarnings(fileName, mc.Warnings)
+	for _, h := range mc.CloneHints() {
+		_, _ = fmt.Fprintf(os.Stderr, "hint: %s: %s\n", fileName, h)
+	}
+	if incomplete := mc.IncompleteBlocks(); len(incomplete) > 0 {
+		unknown := "'??'"
+		switch opts.Format {
+		case convert.OutputEML, convert.OutputBin, convert.OutputChameleon:
+			unknown = "00"
+		case convert.OutputMCT:
+			unknown = "'--'"
 		}
-		blocks[i] = bs
+		_, _ = fmt.Fprintf(os.Stderr, "warning: %s: %d of %d blocks are incomplete, unknown bytes are written as %s\n",
+			fileName, len(incomplete), len(mc.Blocks), unknown)
 	}
-
-	return &mifareCard{
-		UID:    uid,
-		ATQA:   atqa,
-		SAK:    sak,
-		Blocks: blocks,
-	}, nil
 }
 
-// Function that decodes hexadecimal data from a string and returns it as a hexData type
-func decodeHexData(hexStr string) (bs hexData, err error) {
-	bs, err = hex.DecodeString(hexStr)
-	if err != nil {
-		err = fmt.Errorf("failed to parse hex data '%s': %w", hexStr, err)
+// Function that prints the warnings noticed while parsing a dump
+func reportWarnings(fileName string, warnings []string) {
+	if !warningsEnabled() {
+		return
 	}
-	return
-}
-
-// Function that creates an NFC file and writes Mifare card data to it
-func writeNFCFile(fileName string, c *mifareCard) error {
-	nfcFile, err := os.Create(fileName)
-	if err != nil {
-		return fmt.Errorf("failed to create NFC file '%s': %w", fileName, err)
+	for _, w := range warnings {
+		_, _ = fmt.Fprintf(os.Stderr, "warning: %s: %s\n", fileName, w)
 	}
-	defer nfcFile.Close()
-
-	return writeNFC(nfcFile, c)
 }
 
-// Function that writes Mifare card data to a writer in NFC format
-func writeNFC(w io.Writer, c *mifareCard) error {
-	_, err := fmt.Fprintln(w, `Filetype: Flipper NFC device
-Version: 2
-# Nfc device type can be UID, Mifare Ultralight, Mifare Classic, Bank card
-Device type: Mifare Classic
-# UID, ATQA and SAK are common for all formats`)
-	_, err = fmt.Fprintf(w, "UID: %s\n", c.UID)
-	_, err = fmt.Fprintf(w, "ATQA: %s\n", c.ATQA)
-	_, err = fmt.Fprintf(w, "SAK: %s\n", c.SAK)
-	_, err = fmt.Fprintln(w, "# Mifare Classic specific data")
-	mfSize := 0
-	switch len(c.Blocks) {
-	case 64:
-		mfSize = 1
-	case 128:
-		mfSize = 2
-	case 256:
-		mfSize = 4
-	}
-	_, err = fmt.Fprintf(w, "Mifare Classic type: %dK\n", mfSize)
-	_, err = fmt.Fprintln(w, `Data format version: 2
-# Mifare Classic blocks, '??' means unknown data`)
-	for i, block := range c.Blocks {
-		_, err = fmt.Fprintf(w, "Block %d: %s\n", i, block)
+// Function that creates the output file, or uses standard output for "-", and writes the card data to it
+// in the selected format. An existing file is only replaced with force
+func writeOutputFile(fileName string, c convert.Card, opts convert.WriteOptions, force bool) error {
+	if fileName == stdioFileName {
+		return convert.WriteCard(os.Stdout, c, opts)
 	}
 
-	return err
+	if err := convert.CheckOutputFormat(c, opts.Format); err != nil {
+		return err
+	}
+	return writeFileAtomic(fileName, "output file", force, func(w io.Writer) error {
+		return convert.WriteCard(w, c, opts)
+	})
 }
-
@@ -0,0 +1,70 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/dimchansky/proxmark3-to-flipper/pkg/convert"
+)
+
+// Function that runs the merge mode: combines partial dumps of the same card into one Flipper file
+func runMerge(args []string) error {
+	var cfg config
+	fs := flag.NewFlagSet("merge", flag.ExitOnError)
+	fs.StringVar(&cfg.OutputFile, "o", "", "output Flipper file in NFC format or Proxmark3 dump (see -output-format), '-' for stdout")
+	fs.IntVar(&cfg.FormatVersion, "format-version", convert.NFCFormatLatest, "Flipper NFC file format version to write (2, 3 or 4)")
+	fs.BoolVar(&cfg.SwapATQA, "swap-atqa", false, "swap the ATQA bytes of the dumps instead of detecting their order")
+	fs.StringVar(&cfg.OutputFormat, "output-format", "", "output format: "+outputFormatList()+", by default chosen by the output file extension")
+	fs.StringVar(&cfg.InputFormat, "input-format", string(convert.FormatAuto), "input format, detected from the content by default: "+inputFormatList())
+	fs.BoolVar(&cfg.Strict, "strict", false, "reject JSON dumps which are not written the way the official Proxmark3 client writes them")
+	fs.BoolVar(&cfg.Lenient, "lenient", false, "accept JSON dumps of Proxmark3 forks: hex with spaces, missing headers, blocks keyed as \"Block N\"")
+	fs.BoolVar(&cfg.Verbose, "v", false, "log what is parsed and written to standard error")
+	fs.BoolVar(&cfg.Debug, "vv", false, "also log the decisions of the parser: detected format, ATQA byte order, card size, missing blocks")
+	fs.BoolVar(&cfg.Quiet, "quiet", false, "only print errors, no warnings or hints")
+	fs.BoolVar(&cfg.Force, "f", false, "overwrite existing output files, which are left untouched otherwise")
+	fs.BoolVar(&cfg.Force, "force", false, "same as -f")
+	fs.Usage = func() {
+		_, _ = fmt.Fprintf(fs.Output(), "Usage of %s merge [flags] DUMP...:\n", os.Args[0])
+		fs.PrintDefaults()
+	}
+	usage = fs.Usage
+	if err := applyDefaults(fs); err != nil {
+		return err
+	}
+	_ = fs.Parse(args)
+	if err := cfg.checkInputFormat(); err != nil {
+		return err
+	}
+
+	if err := cfg.applyLogLevel(); err != nil {
+		return err
+	}
+	if err := cfg.checkOutputFormat(); err != nil {
+		return err
+	}
+
+	if fs.NArg() < 2 {
+		return usageError("please provide at least two dumps to merge")
+	}
+	if cfg.OutputFile == "" {
+		return usageError("please provide output Flipper file in NFC format")
+	}
+
+	cards := make([]*convert.MifareCard, 0, fs.NArg())
+	for _, name := range fs.Args() {
+		mc, err := parseMifareFile(name, cfg.parseOptions())
+		if err != nil {
+			return err
+		}
+		cards = append(cards, mc)
+	}
+
+	merged, err := convert.MergeMifare(cards...)
+	if err != nil {
+		return err
+	}
+	reportCard(cfg.OutputFile, merged, cfg.writeOptions())
+
+	return writeOutputFile(cfg.OutputFile, merged, cfg.writeOptions(), cfg.Force)
+}
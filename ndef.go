@@ -0,0 +1,158 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/dimchansky/proxmark3-to-flipper/pkg/convert"
+)
+
+// Struct holding the NDEF content of a dump, as printed by the ndef mode
+type ndefReport struct {
+	File     string                `json:"file"`
+	Card     string                `json:"card"`
+	Messages []convert.NDEFMessage `json:"messages"`
+	Warnings []string              `json:"warnings,omitempty"`
+}
+
+// Function that runs the ndef mode: decodes the NDEF records of a Mifare Classic or Ultralight/NTAG dump
+func runNDEF(args []string) error {
+	var (
+		cfg       config
+		inputFile string
+		jsonOut   bool
+	)
+	fs := flag.NewFlagSet("ndef", flag.ExitOnError)
+	fs.StringVar(&inputFile, "i", "", "input Mifare Classic dump in any supported format, or Proxmark3 Ultralight/NTAG JSON dump, '-' for stdin")
+	fs.BoolVar(&jsonOut, "json", false, "print the records as a JSON report")
+	fs.StringVar(&cfg.InputFormat, "input-format", string(convert.FormatAuto), "input format, detected from the content by default: "+inputFormatList())
+	fs.BoolVar(&cfg.Strict, "strict", false, "reject JSON dumps which are not written the way the official Proxmark3 client writes them")
+	fs.BoolVar(&cfg.Lenient, "lenient", false, "accept JSON dumps of Proxmark3 forks: hex with spaces, missing headers, blocks keyed as \"Block N\"")
+	fs.BoolVar(&cfg.Verbose, "v", false, "log what is parsed to standard error")
+	fs.BoolVar(&cfg.Debug, "vv", false, "also log the decisions of the parser: detected format, ATQA byte order, card size, missing blocks")
+	fs.BoolVar(&cfg.Quiet, "quiet", false, "only print errors, no warnings")
+	fs.Usage = func() {
+		_, _ = fmt.Fprintf(fs.Output(), "Usage of %s ndef [flags] [DUMP]:\n", os.Args[0])
+		fs.PrintDefaults()
+	}
+	usage = fs.Usage
+	if err := applyDefaults(fs); err != nil {
+		return err
+	}
+	_ = fs.Parse(args)
+	if err := cfg.checkInputFormat(); err != nil {
+		return err
+	}
+	if err := cfg.applyLogLevel(); err != nil {
+		return err
+	}
+
+	inputFile, err := inputArg(fs, inputFile)
+	if err != nil {
+		return err
+	}
+	if inputFile == "" {
+		return usageError("please provide input Proxmark3 dump file")
+	}
+
+	data, err := readInputFile(inputFile)
+	if err != nil {
+		return err
+	}
+
+	opts := cfg.parseOptions()
+	opts.Logger = logger.With("file", inputFile)
+	report := ndefReport{File: inputFile}
+	var ndefErr error
+	card, err := convert.ParseWithOptions(bytes.NewReader(data), opts)
+	switch {
+	case err == nil:
+		mc, ok := card.(*convert.MifareCard)
+		if !ok {
+			cardType := newConversionReport(conversionResult{Card: card}).CardType
+			return fmt.Errorf("no NDEF data in '%s': %s cards hold no NDEF records", inputFile, cardType)
+		}
+		report.Card = "Mifare Classic"
+		report.Warnings = mc.Warnings
+		report.Messages, ndefErr = mc.NDEF()
+	case errors.Is(err, convert.ErrUnsupportedFileType) && isJSONInput(cfg.InputFormat, data):
+		// Ultralight/NTAG dumps are no convert.Card, so they are only read from Proxmark3 JSON
+		uc, err := convert.ParseProxmark3UltralightJSONWithOptions(bytes.NewReader(data), opts)
+		if err != nil {
+			return err
+		}
+		report.Card = "Mifare Ultralight/NTAG"
+		report.Messages, ndefErr = uc.NDEF()
+	default:
+		return err
+	}
+
+	if ndefErr != nil {
+		if len(report.Messages) == 0 {
+			return fmt.Errorf("no NDEF data in '%s': %w", inputFile, ndefErr)
+		}
+		report.Warnings = append(report.Warnings, ndefErr.Error())
+	}
+
+	if jsonOut {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(report)
+	}
+	return printNDEF(os.Stdout, &report)
+}
+
+// Function that reports whether a dump is read as Proxmark3 JSON, selected with -input-format or detected
+func isJSONInput(inputFormat string, data []byte) bool {
+	format := convert.InputFormat(inputFormat)
+	if format == convert.FormatAuto {
+		format = convert.DetectFormat(data)
+	}
+	return format == convert.FormatProxmark3JSON
+}
+
+// Function that reads a whole input file, or standard input for "-", refusing dumps larger than convert.MaxDumpSize
+func readInputFile(fileName string) ([]byte, error) {
+	if fileName == stdioFileName {
+		data, err := convert.ReadDump(os.Stdin)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read standard input: %w", err)
+		}
+		return data, nil
+	}
+	f, err := os.Open(fileName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read '%s': %w", fileName, err)
+	}
+	defer f.Close()
+	data, err := convert.ReadDump(f)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read '%s': %w", fileName, err)
+	}
+	return data, nil
+}
+
+// Function that prints the NDEF records of a dump, one per line
+func printNDEF(w io.Writer, r *ndefReport) error {
+	reportWarnings(r.File, r.Warnings)
+
+	if _, err := fmt.Fprintf(w, "%s: %s, %d NDEF messages\n", r.File, r.Card, len(r.Messages)); err != nil {
+		return err
+	}
+	for i, msg := range r.Messages {
+		if _, err := fmt.Fprintf(w, "Message %d\n", i+1); err != nil {
+			return err
+		}
+		for j, rec := range msg.Records {
+			if _, err := fmt.Fprintf(w, "  Record %d: %s\n", j+1, rec); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
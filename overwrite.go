@@ -0,0 +1,164 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"github.com/dimchansky/proxmark3-to-flipper/pkg/convert"
+)
+
+// Error returned when an output file already exists and -f was not given
+var errOutputExists = fmt.Errorf("%w, use -f to overwrite it", fs.ErrExist)
+
+// Function that writes a file through a temporary file in the same directory, moved in place once write
+// succeeded, so a failed write neither leaves a broken file behind nor destroys the file it was replacing.
+// An existing file is refused unless force is set; what names the file in the errors
+func writeFileAtomic(name, what string, force bool, write func(io.Writer) error) error {
+	perm := fs.FileMode(0o644)
+	if st, err := os.Stat(name); err == nil {
+		if !force {
+			return fmt.Errorf("failed to create %s '%s': %w", what, name, errOutputExists)
+		}
+		perm = st.Mode().Perm()
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(name), "."+filepath.Base(name)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("failed to create %s '%s': %w", what, name, err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if err := write(tmp); err != nil {
+		_ = tmp.Close()
+		return err
+	}
+	if err := tmp.Chmod(perm); err != nil {
+		_ = tmp.Close()
+		return fmt.Errorf("failed to create %s '%s': %w", what, name, err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write %s '%s': %w", what, name, err)
+	}
+
+	if force {
+		err = os.Rename(tmp.Name(), name)
+	} else {
+		// a hard link never replaces a file created since the check above
+		err = os.Link(tmp.Name(), name)
+		switch {
+		case errors.Is(err, fs.ErrExist):
+			err = errOutputExists
+		case err != nil:
+			// file systems without hard links
+			err = os.Rename(tmp.Name(), name)
+		}
+	}
+	if err != nil {
+		return fmt.Errorf("failed to create %s '%s': %w", what, name, err)
+	}
+	return nil
+}
+
+// Function that checks that an output file can be written, the way writeFileAtomic does it
+func checkOutputFile(name string, force bool) error {
+	if name == stdioFileName || force {
+		return nil
+	}
+	if _, err := os.Stat(name); err == nil {
+		return fmt.Errorf("failed to create output file '%s': %w", name, errOutputExists)
+	}
+	return nil
+}
+
+// Function that returns the files written along with an output: the -sha256 checksum file and the -cache
+// shadow file and key cache
+func companionFiles(cfg *config, output string, c convert.Card) []string {
+	var names []string
+	if cfg.Checksum && output != stdioFileName {
+		names = append(names, output+".sha256")
+	}
+	if mc, ok := c.(*convert.MifareCard); ok && cfg.EmulationCache {
+		shadowFile, keysFile := emulationCacheFiles(output, mc)
+		names = append(names, shadowFile, keysFile)
+	}
+	return names
+}
+
+// Function that checks the files written along with an output the way checkOutputFile does it, so an existing
+// one stops the conversion before the output is written
+func checkCompanionFiles(cfg *config, output string, c convert.Card) error {
+	for _, name := range companionFiles(cfg, output, c) {
+		if err := checkOutputFile(name, cfg.Force || keyCacheWritten(name)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Function that reports what the conversion of a card would write with -dry-run, without creating any file.
+// The output is rendered in memory to catch the errors of the writer, and refused like in a real run
+// when it already exists
+func reportDryRun(cfg *config, input, output string, c convert.Card) error {
+	if err := checkOutputFile(output, cfg.Force); err != nil {
+		return err
+	}
+	if err := checkCompanionFiles(cfg, output, c); err != nil {
+		return err
+	}
+
+	var buf bytes.Buffer
+	format := string(cfg.outputFormat())
+	if cfg.template != nil {
+		format = "template " + cfg.Template
+		if err := renderTemplate(&buf, cfg, input, c); err != nil {
+			return err
+		}
+	} else if err := convert.WriteCard(&buf, c, cfg.writeOptions()); err != nil {
+		return err
+	}
+
+	action, name := "write", output
+	if output == stdioFileName {
+		name = "standard output"
+	} else if _, err := os.Stat(output); err == nil {
+		action = "overwrite"
+	}
+	_, _ = fmt.Fprintf(os.Stderr, "dry run: would %s %s (%s, %d bytes)\n", action, name, format, buf.Len())
+	for _, name := range companionFiles(cfg, output, c) {
+		action := "write"
+		if _, err := os.Stat(name); err == nil {
+			action = "overwrite"
+		}
+		_, _ = fmt.Fprintf(os.Stderr, "dry run: would %s %s\n", action, name)
+	}
+	return nil
+}
+
+// Function that reports the files a -dry-run would write once all dumps are converted: the key dictionary,
+// the T5577 blocks and the upload to the Flipper
+func reportDryRunExtras(cfg *config) error {
+	for _, f := range []struct {
+		name string
+		what string
+	}{
+		{cfg.KeysFile, "key dictionary"},
+		{cfg.T5577File, "T5577 blocks"},
+	} {
+		if f.name == "" {
+			continue
+		}
+		if err := checkOutputFile(f.name, cfg.Force); err != nil {
+			return err
+		}
+		_, _ = fmt.Fprintf(os.Stderr, "dry run: would write the %s to %s\n", f.what, f.name)
+	}
+	if cfg.FlipperUpload {
+		_, _ = fmt.Fprintln(os.Stderr, "dry run: would upload the converted files to the Flipper")
+	}
+	return nil
+}
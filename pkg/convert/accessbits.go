@@ -0,0 +1,109 @@
+package convert
+
+import "fmt"
+
+// Access conditions of a Mifare Classic sector: the C1 C2 C3 bits of its three data block groups
+// and of its trailer, packed as C1<<2 | C2<<1 | C3
+type AccessBits [4]byte
+
+// Function that decodes the access bits stored in bytes 6 to 8 of a sector trailer, checking that
+// every bit matches its inverted copy
+func DecodeAccessBits(trailer Block) (AccessBits, error) {
+	var ab AccessBits
+	for i := 6; i <= 8; i++ {
+		if len(trailer.Data) <= i || trailer.IsUnknown(i) {
+			return ab, fmt.Errorf("access bits are unknown")
+		}
+	}
+
+	b6, b7, b8 := trailer.Data[6], trailer.Data[7], trailer.Data[8]
+	c1, c2, c3 := b7>>4, b8&0x0F, b8>>4
+	nc1, nc2, nc3 := b6&0x0F, b6>>4, b7&0x0F
+	if c1 != ^nc1&0x0F || c2 != ^nc2&0x0F || c3 != ^nc3&0x0F {
+		return ab, fmt.Errorf("access bits %02X %02X %02X do not match their inverted copies", b6, b7, b8)
+	}
+
+	for i := range ab {
+		ab[i] = (c1>>uint(i)&1)<<2 | (c2>>uint(i)&1)<<1 | c3>>uint(i)&1
+	}
+	return ab, nil
+}
+
+// Permissions granted by the access conditions of data blocks, indexed by C1<<2 | C2<<1 | C3:
+// read, write, increment, decrement/transfer/restore
+var dataAccess = [8][4]string{
+	{"A|B", "A|B", "A|B", "A|B"},
+	{"A|B", "never", "never", "A|B"},
+	{"A|B", "never", "never", "never"},
+	{"B", "B", "never", "never"},
+	{"A|B", "B", "never", "never"},
+	{"B", "never", "never", "never"},
+	{"A|B", "B", "B", "A|B"},
+	{"never", "never", "never", "never"},
+}
+
+// Permissions granted by the access conditions of sector trailers, indexed by C1<<2 | C2<<1 | C3:
+// Key A write, access bits read and write, Key B read and write (Key A can never be read)
+var trailerAccess = [8][5]string{
+	{"A", "A", "never", "A", "A"},
+	{"A", "A", "A", "A", "A"},
+	{"never", "A", "never", "A", "never"},
+	{"B", "A|B", "B", "never", "B"},
+	{"B", "A|B", "never", "never", "B"},
+	{"never", "A|B", "B", "never", "never"},
+	{"never", "A|B", "never", "never", "never"},
+	{"never", "A|B", "never", "never", "never"},
+}
+
+// Function that describes which key may read and write a data block group (0 to 2) or the trailer (3)
+func (ab AccessBits) Describe(group int) string {
+	c := ab[group] & 7
+	if group == 3 {
+		t := trailerAccess[c]
+		return fmt.Sprintf("key A write %s, access bits read %s write %s, key B read %s write %s", t[0], t[1], t[2], t[3], t[4])
+	}
+	d := dataAccess[c]
+	return fmt.Sprintf("read %s, write %s, increment %s, decrement %s", d[0], d[1], d[2], d[3])
+}
+
+// Struct holding the decoded access conditions of a sector, one line per block or block group
+// followed by the trailer; Error is set instead when the access bits are unknown or invalid
+type SectorAccess struct {
+	Sector     int      `json:"sector"`
+	Conditions []string `json:"conditions,omitempty"`
+	Error      string   `json:"error,omitempty"`
+}
+
+// Function that decodes the access conditions of a sector into human-readable lines
+func (c *MifareCard) SectorAccess(sector int) SectorAccess {
+	sa := SectorAccess{Sector: sector}
+	trailer := ClassicSectorTrailer(sector)
+	ab, err := DecodeAccessBits(c.Blocks[trailer])
+	if err != nil {
+		sa.Error = err.Error()
+		return sa
+	}
+
+	first := ClassicSectorFirstBlock(sector)
+	groupSize := (ClassicSectorBlocks(sector) - 1) / 3
+	for g := 0; g < 3; g++ {
+		start := first + g*groupSize
+		blocks := fmt.Sprintf("block %d", start)
+		if groupSize > 1 {
+			blocks = fmt.Sprintf("blocks %d-%d", start, start+groupSize-1)
+		}
+		sa.Conditions = append(sa.Conditions, fmt.Sprintf("%s: %s", blocks, ab.Describe(g)))
+	}
+	sa.Conditions = append(sa.Conditions, fmt.Sprintf("trailer %d: %s", trailer, ab.Describe(3)))
+	return sa
+}
+
+// Function that decodes the access conditions of every sector of the card
+func (c *MifareCard) AccessConditions() []SectorAccess {
+	sectors := ClassicSectorCount(len(c.Blocks))
+	access := make([]SectorAccess, sectors)
+	for s := range access {
+		access[s] = c.SectorAccess(s)
+	}
+	return access
+}
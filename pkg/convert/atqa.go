@@ -0,0 +1,55 @@
+package convert
+
+import "fmt"
+
+// Function that reports whether b looks like the low ATQA byte: exactly one of the
+// anticollision bit frame bits 0-4 is set and the RFU bit 5 is clear
+func isATQALowByte(b byte) bool {
+	frame := b & 0x1F
+	return b&0x20 == 0 && frame != 0 && frame&(frame-1) == 0
+}
+
+// Function that returns the ATQA in the order it is sent by the card (low byte first), the order the
+// card model keeps it in. Input in the other order is detected and swapped back; with force set the
+// input is swapped unconditionally. The returned warning is empty unless the order could not be decided.
+func NormalizeATQA(atqa HexData, force bool) (HexData, string, error) {
+	if len(atqa) != 2 {
+		return nil, "", &FieldError{Field: "ATQA", Err: fmt.Errorf("expecting 2 bytes, got %d", len(atqa))}
+	}
+
+	swapped := HexData{atqa[1], atqa[0]}
+	if force {
+		return swapped, "", nil
+	}
+
+	lowFirst, lowSecond := isATQALowByte(atqa[0]), isATQALowByte(atqa[1])
+	switch {
+	case lowFirst && !lowSecond:
+		return atqa, "", nil
+	case lowSecond && !lowFirst:
+		return swapped, "", nil
+	}
+	return atqa, fmt.Sprintf("cannot tell the byte order of ATQA %s, keeping it as is (use -swap-atqa to swap it)", atqa), nil
+}
+
+// Function that reduces the SAK to the single byte Flipper expects, dropping a zero padding byte
+func normalizeSAK(sak HexData) (HexData, error) {
+	switch {
+	case len(sak) == 1:
+		return sak, nil
+	case len(sak) == 2 && sak[0] == 0:
+		return sak[1:], nil
+	case len(sak) == 2 && sak[1] == 0:
+		return sak[:1], nil
+	}
+	return nil, &FieldError{Field: "SAK", Err: fmt.Errorf("expecting 1 byte, got %d", len(sak))}
+}
+
+// Function that returns the ATQA in the byte order of the given Flipper NFC format version:
+// version 2 files keep the low byte first, later versions store the high byte first
+func flipperATQA(atqa HexData, version int) HexData {
+	if version <= NFCFormatV2 || len(atqa) != 2 {
+		return atqa
+	}
+	return HexData{atqa[1], atqa[0]}
+}
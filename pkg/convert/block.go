@@ -0,0 +1,95 @@
+package convert
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Size in bytes of a Mifare Classic block
+const ClassicBlockSize = 16
+
+// Struct representing a single card block in which individual bytes may be unknown
+type Block struct {
+	Data    HexData
+	Unknown []bool // marks the bytes of Data that are unknown, nil when every byte is known
+}
+
+// Function that returns a block of the given size whose bytes are all unknown
+func UnknownBlock(size int) Block {
+	b := Block{Data: make(HexData, size), Unknown: make([]bool, size)}
+	for i := range b.Unknown {
+		b.Unknown[i] = true
+	}
+	return b
+}
+
+// Function that reports whether the byte at index i is unknown
+func (b Block) IsUnknown(i int) bool {
+	return b.Unknown != nil && b.Unknown[i]
+}
+
+// Function that returns the number of unknown bytes in the block
+func (b Block) UnknownCount() int {
+	n := 0
+	for _, u := range b.Unknown {
+		if u {
+			n++
+		}
+	}
+	return n
+}
+
+// Function that reports whether every byte of the block is known
+func (b Block) IsComplete() bool {
+	return b.UnknownCount() == 0
+}
+
+// String method for Block type to print hexadecimal data with '??' for unknown bytes
+func (b Block) String() string {
+	var sb strings.Builder
+
+	for i, v := range b.Data {
+		if i > 0 {
+			sb.WriteByte(' ')
+		}
+		if b.IsUnknown(i) {
+			sb.WriteString("??")
+		} else {
+			sb.WriteString(fmt.Sprintf("%02X", v))
+		}
+	}
+
+	return sb.String()
+}
+
+// MarshalText method for Block type to encode the block the way String prints it
+func (b Block) MarshalText() ([]byte, error) {
+	return []byte(b.String()), nil
+}
+
+// Function that decodes a block from a hex string in which unknown bytes are written as '??'
+func DecodeBlock(hexStr string) (Block, error) {
+	if len(hexStr)%2 != 0 {
+		return Block{}, fmt.Errorf("failed to parse hex data '%s': odd length", hexStr)
+	}
+
+	var b Block
+	for i := 0; i < len(hexStr); i += 2 {
+		pair := hexStr[i : i+2]
+		if pair == "??" {
+			if b.Unknown == nil {
+				b.Unknown = make([]bool, len(hexStr)/2)
+			}
+			b.Unknown[i/2] = true
+			b.Data = append(b.Data, 0)
+			continue
+		}
+		bs, err := DecodeHexData(pair)
+		if err != nil {
+			return Block{}, fmt.Errorf("failed to parse hex data '%s': %w", hexStr, err)
+		}
+		b.Data = append(b.Data, bs[0])
+	}
+
+	return b, nil
+}
@@ -0,0 +1,72 @@
+package convert
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+)
+
+// Struct holding the card identification written by the manufacturer into block 0
+type Block0Info struct {
+	UID  HexData
+	ATQA HexData // low byte first, as sent by the card
+	SAK  HexData
+}
+
+// Function that decodes the manufacturer data of block 0. A 4-byte UID is recognized by its BCC
+// byte; without a matching BCC the block is read with the 7-byte UID layout.
+func DecodeBlock0(b Block) (*Block0Info, error) {
+	known := func(n int) bool {
+		if len(b.Data) < n {
+			return false
+		}
+		for i := 0; i < n; i++ {
+			if b.IsUnknown(i) {
+				return false
+			}
+		}
+		return true
+	}
+
+	d := b.Data
+	switch {
+	case known(8) && bcc(d[:4]) == d[4]:
+		return &Block0Info{UID: d[0:4], SAK: d[5:6], ATQA: d[6:8]}, nil
+	case known(10):
+		return &Block0Info{UID: d[0:7], SAK: d[7:8], ATQA: d[8:10]}, nil
+	}
+	return nil, errors.New("block 0 holds no recognizable UID, SAK and ATQA")
+}
+
+// Function that computes the BCC of a UID, the XOR of all its bytes
+func bcc(uid []byte) byte {
+	var x byte
+	for _, b := range uid {
+		x ^= b
+	}
+	return x
+}
+
+// Function that fills the card identification fields missing from the dump with the ones of block 0
+// and cross-checks the fields present in both, returning a warning for every mismatch
+func reconcileBlock0(uid, atqa, sak *HexData, b0 *Block0Info) []string {
+	var warnings []string
+	fields := []struct {
+		name       string
+		card, blk0 *HexData
+	}{
+		{"UID", uid, &b0.UID},
+		{"ATQA", atqa, &b0.ATQA},
+		{"SAK", sak, &b0.SAK},
+	}
+	for _, f := range fields {
+		switch {
+		case len(*f.card) == 0:
+			*f.card = append(HexData(nil), *f.blk0...)
+			warnings = append(warnings, fmt.Sprintf("card %s missing from the dump, derived %s from block 0", f.name, *f.card))
+		case !bytes.Equal(*f.card, *f.blk0):
+			warnings = append(warnings, fmt.Sprintf("card %s %s does not match %s in block 0", f.name, *f.card, *f.blk0))
+		}
+	}
+	return warnings
+}
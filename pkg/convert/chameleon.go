@@ -0,0 +1,142 @@
+package convert
+
+import (
+	"crypto/rand"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Tag types of the Mifare Classic cards saved by the Chameleon Ultra GUI, by number of blocks
+var chameleonTagTypes = map[int]string{
+	20:  "TagType.mifareMini",
+	64:  "TagType.mifare1K",
+	128: "TagType.mifare2K",
+	256: "TagType.mifare4K",
+}
+
+// Color the Chameleon Ultra GUI gives to cards saved without one (opaque grey, ARGB)
+const chameleonDefaultColor = 0xFF9E9E9E
+
+// Struct representing a card saved by the Chameleon Ultra GUI app, as exported to JSON
+type chameleonCardJSON struct {
+	ID    string  `json:"id"`
+	UID   string  `json:"uid"`
+	SAK   int     `json:"sak"`
+	ATQA  []int   `json:"atqa"`
+	ATS   []int   `json:"ats"`
+	Name  string  `json:"name"`
+	Tag   string  `json:"tag"`
+	Data  [][]int `json:"data"`
+	Color int64   `json:"color"`
+}
+
+// Function that reports whether a JSON tag field names a Mifare Classic card saved by the Chameleon Ultra GUI
+func isChameleonCard(tag string) bool {
+	return strings.HasPrefix(tag, "TagType.mifare")
+}
+
+// Function that converts a list of JSON numbers into bytes, checking their range
+func chameleonBytes(field string, values []int) (HexData, error) {
+	data := make(HexData, len(values))
+	for i, v := range values {
+		if v < 0 || v > 0xFF {
+			return nil, &FieldError{Field: field, Err: fmt.Errorf("byte %d out of range: %d", i, v)}
+		}
+		data[i] = byte(v)
+	}
+	return data, nil
+}
+
+// Function that parses a Mifare Classic card exported by the Chameleon Ultra GUI app: the UID as hex, SAK
+// and ATQA as numbers and every block as a list of 16 numbers
+func ParseChameleonJSON(r io.Reader, opts ParseOptions) (*MifareCard, error) {
+	var card chameleonCardJSON
+	if err := decodeJSON(r, &card); err != nil {
+		return nil, fmt.Errorf("failed to decode Chameleon JSON file: %w", err)
+	}
+	if !isChameleonCard(card.Tag) {
+		return nil, fmt.Errorf("%w: Chameleon card type '%s'", ErrUnsupportedFileType, card.Tag)
+	}
+	if len(card.Data) == 0 {
+		return nil, fmt.Errorf("Chameleon card '%s' holds no blocks", card.Name)
+	}
+
+	atqa, err := chameleonBytes("ATQA", card.ATQA)
+	if err != nil {
+		return nil, err
+	}
+	// the GUI keeps the ATQA high byte first, turn it to the low byte first order of the dumps so that
+	// the detection of the byte order and -swap-atqa work like for the other formats
+	for i, j := 0, len(atqa)-1; i < j; i, j = i+1, j-1 {
+		atqa[i], atqa[j] = atqa[j], atqa[i]
+	}
+	sak := ""
+	if card.SAK != 0 {
+		sak = fmt.Sprintf("%02X", card.SAK)
+	}
+	blocks := make(map[string]string, len(card.Data))
+	for i, values := range card.Data {
+		data, err := chameleonBytes(fmt.Sprintf("block %d", i), values)
+		if err != nil {
+			return nil, err
+		}
+		if len(data) != ClassicBlockSize {
+			return nil, &BlockError{Block: i, Err: fmt.Errorf("expecting %d bytes, got %d", ClassicBlockSize, len(data))}
+		}
+		blocks[fmt.Sprint(i)] = fmt.Sprintf("%X", []byte(data))
+	}
+
+	return newMifareCard(strings.ReplaceAll(card.UID, " ", ""), fmt.Sprintf("%X", []byte(atqa)), sak, blocks, opts)
+}
+
+// Function that returns a random version 4 UUID, the identifier the Chameleon Ultra GUI gives saved cards
+func newUUID() (string, error) {
+	var u [16]byte
+	if _, err := rand.Read(u[:]); err != nil {
+		return "", err
+	}
+	u[6] = u[6]&0x0F | 0x40
+	u[8] = u[8]&0x3F | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", u[0:4], u[4:6], u[6:8], u[8:10], u[10:16]), nil
+}
+
+// Function that writes a Mifare card as a Chameleon Ultra GUI card export, named after its UID.
+// Unknown bytes are written as 00 since the Chameleon cannot emulate them otherwise
+func WriteChameleonJSON(w io.Writer, c *MifareCard) error {
+	tag, ok := chameleonTagTypes[len(c.Blocks)]
+	if !ok {
+		return fmt.Errorf("%w: %d blocks", ErrUnsupportedSize, len(c.Blocks))
+	}
+	id, err := newUUID()
+	if err != nil {
+		return err
+	}
+
+	card := chameleonCardJSON{
+		ID:    id,
+		UID:   fmt.Sprintf("%X", []byte(c.UID)),
+		Name:  "Mifare " + c.UID.String(),
+		Tag:   tag,
+		ATS:   []int{},
+		Color: chameleonDefaultColor,
+	}
+	if len(c.SAK) == 1 {
+		card.SAK = int(c.SAK[0])
+	}
+	// the GUI shows the ATQA high byte first
+	for i := len(c.ATQA) - 1; i >= 0; i-- {
+		card.ATQA = append(card.ATQA, int(c.ATQA[i]))
+	}
+	for _, b := range c.Blocks {
+		data := knownOrZero(b)
+		values := make([]int, len(data))
+		for i, v := range data {
+			values[i] = int(v)
+		}
+		card.Data = append(card.Data, values)
+	}
+
+	return json.NewEncoder(w).Encode(card)
+}
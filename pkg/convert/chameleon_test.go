@@ -0,0 +1,58 @@
+package convert
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+// Chameleon Ultra GUI export of a Mifare Classic 1K whose ATQA 01 04 (high byte first) has two bytes which
+// could both be the low one, so only the byte order of the format tells them apart
+const chameleonExport = `{"id":"6f1c2b8e-3a4d-4e5f-8a9b-0c1d2e3f4a5b","uid":"11223344","sak":8,"atqa":[1,4],"ats":[],` +
+	`"name":"Office","tag":"TagType.mifare1K","data":[` +
+	`[17,34,51,68,68,8,4,1,98,99,100,101,102,103,104,105],` +
+	`[0,0,0,0,0,0,0,0,0,0,0,0,0,0,0,0]],"color":4288585374}`
+
+func TestParseChameleonJSONATQA(t *testing.T) {
+	tests := []struct {
+		name string
+		swap bool
+		want string
+	}{
+		{"GUI order", false, "04 01"},
+		{"swap-atqa", true, "01 04"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mc, err := ParseChameleonJSON(strings.NewReader(chameleonExport), ParseOptions{SwapATQA: tt.swap})
+			if err != nil {
+				t.Fatalf("ParseChameleonJSON: %v", err)
+			}
+			if got := mc.ATQA.String(); got != tt.want {
+				t.Errorf("ATQA = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestChameleonJSONRoundTrip(t *testing.T) {
+	mc, err := ParseChameleonJSON(strings.NewReader(chameleonExport), ParseOptions{})
+	if err != nil {
+		t.Fatalf("ParseChameleonJSON: %v", err)
+	}
+	var buf bytes.Buffer
+	if err := WriteChameleonJSON(&buf, mc); err != nil {
+		t.Fatalf("WriteChameleonJSON: %v", err)
+	}
+	if !strings.Contains(buf.String(), `"atqa":[1,4]`) {
+		t.Errorf("WriteChameleonJSON wrote ATQA in the wrong order: %s", buf.String())
+	}
+	again, err := ParseChameleonJSON(&buf, ParseOptions{})
+	if err != nil {
+		t.Fatalf("ParseChameleonJSON of the written card: %v", err)
+	}
+	if !bytes.Equal(again.ATQA, mc.ATQA) || !bytes.Equal(again.UID, mc.UID) || !bytes.Equal(again.SAK, mc.SAK) {
+		t.Errorf("round trip changed the identification: %s %s %s, want %s %s %s",
+			again.UID, again.ATQA, again.SAK, mc.UID, mc.ATQA, mc.SAK)
+	}
+}
@@ -0,0 +1,256 @@
+// Package convert turns Proxmark3 dumps into files understood by the Flipper Zero
+package convert
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+)
+
+// Errors returned by the parsers, to be matched with errors.Is
+var (
+	ErrNotProxmark3        = errors.New("JSON file must be produced by Proxmark3")
+	ErrUnsupportedFileType = errors.New("expecting Mifare card dump")
+	ErrMissingBlock        = errors.New("cannot find Mifare card data")
+	ErrUnsupportedSize     = errors.New("unsupported Mifare Classic size")
+	ErrNoLFCredential      = errors.New("no EM410x, HID or Indala credential found in Proxmark3 LF output")
+	ErrNoMifareBlocks      = errors.New("no Mifare Classic blocks found in Proxmark3 client output")
+	ErrNotCanonical        = errors.New("dump is not in canonical Proxmark3 form")
+	ErrUnrecognisedFormat  = errors.New("unrecognised dump format")
+)
+
+// FieldError reports a card field that could not be decoded
+type FieldError struct {
+	Field string
+	Err   error
+}
+
+// Error method for FieldError to satisfy the error interface
+func (e *FieldError) Error() string {
+	return fmt.Sprintf("cannot parse card %s: %v", e.Field, e.Err)
+}
+
+// Unwrap method for FieldError to expose the underlying error
+func (e *FieldError) Unwrap() error {
+	return e.Err
+}
+
+// BlockError reports a problem with a single block of a dump
+type BlockError struct {
+	Block int
+	Err   error
+}
+
+// Error method for BlockError to satisfy the error interface
+func (e *BlockError) Error() string {
+	return fmt.Sprintf("block %d: %v", e.Block, e.Err)
+}
+
+// Unwrap method for BlockError to expose the underlying error
+func (e *BlockError) Unwrap() error {
+	return e.Err
+}
+
+// Card is implemented by every card model that can be written as a Flipper file
+type Card interface {
+	// FlipperExt returns the extension of the Flipper file the card is stored in
+	FlipperExt() string
+	writeFlipper(w io.Writer, opts WriteOptions) error
+}
+
+// WriteOptions tunes how cards are written to Flipper files
+type WriteOptions struct {
+	// Flipper NFC file format version, NFCFormatLatest when zero
+	NFCVersion int
+	// Format of the output, a Flipper file when empty
+	Format OutputFormat
+	// Precede every Mifare Classic sector trailer with comments decoding its access conditions
+	AnnotateAccess bool
+	// Where the card comes from, written as comments into Flipper files when set
+	Provenance *Provenance
+}
+
+// Function that writes any card to a writer in the matching Flipper format
+func WriteFlipper(w io.Writer, c Card) error {
+	return c.writeFlipper(w, WriteOptions{})
+}
+
+// Function that writes any card to a writer in the matching Flipper format, tuned by the options
+func WriteFlipperWithOptions(w io.Writer, c Card, opts WriteOptions) error {
+	if opts.Provenance == nil {
+		return c.writeFlipper(w, opts)
+	}
+
+	var buf bytes.Buffer
+	if err := c.writeFlipper(&buf, opts); err != nil {
+		return err
+	}
+	_, err := w.Write(insertProvenance(buf.Bytes(), opts.Provenance))
+	return err
+}
+
+// Function that writes any card in the output format selected by the options, a Flipper file by default.
+// The Proxmark3 formats only hold Mifare Classic cards and carry no provenance
+func WriteCard(w io.Writer, c Card, opts WriteOptions) error {
+	if err := CheckOutputFormat(c, opts.Format); err != nil {
+		return err
+	}
+	if opts.Format == "" || opts.Format == OutputFlipper {
+		return WriteFlipperWithOptions(w, c, opts)
+	}
+
+	mc := c.(*MifareCard)
+	switch opts.Format {
+	case OutputProxmark3JSON:
+		return WriteProxmark3JSON(w, mc)
+	case OutputEML:
+		return WriteEML(w, mc)
+	case OutputBin:
+		return WriteBin(w, mc)
+	case OutputMCT:
+		return WriteMCT(w, mc)
+	case OutputChameleon:
+		return WriteChameleonJSON(w, mc)
+	}
+	return fmt.Errorf("unsupported output format '%s'", opts.Format)
+}
+
+// Function that checks that a card can be written in an output format, so the output file is left alone
+// when it cannot
+func CheckOutputFormat(c Card, format OutputFormat) error {
+	switch format {
+	case "", OutputFlipper:
+		return nil
+	case OutputProxmark3JSON, OutputEML, OutputBin, OutputMCT, OutputChameleon:
+		if _, ok := c.(*MifareCard); !ok {
+			return fmt.Errorf("%s output only holds Mifare Classic cards", format)
+		}
+		return nil
+	}
+	return fmt.Errorf("unsupported output format '%s'", format)
+}
+
+// ParseOptions tunes how dumps are parsed
+type ParseOptions struct {
+	// Swap the ATQA bytes of the dump instead of detecting their order
+	SwapATQA bool
+	// Format of the input, detected from the content when empty or FormatAuto
+	Format InputFormat
+	// How strictly Proxmark3 JSON dumps are checked, ParseDefault when empty
+	Mode ParseMode
+	// Receives the decisions of the parser at debug level, nothing is logged when nil
+	Logger *slog.Logger
+}
+
+// Function that logs a decision of the parser to the logger of the options, if any
+func (o ParseOptions) debug(msg string, args ...interface{}) {
+	if o.Logger != nil {
+		o.Logger.Debug(msg, args...)
+	}
+}
+
+// Function that logs warnings about a card which has no field to keep them, to the logger of the options if any
+func (o ParseOptions) warn(warnings []string) {
+	if o.Logger == nil {
+		return
+	}
+	for _, w := range warnings {
+		o.Logger.Warn(w)
+	}
+}
+
+// Function that detects the format of the input from its content and parses it with the matching parser
+func Parse(r io.Reader) (Card, error) {
+	return ParseWithOptions(r, ParseOptions{})
+}
+
+// Function that parses the input with the parser of the format selected by the options, or detected
+// from the content like Parse does
+func ParseWithOptions(r io.Reader, opts ParseOptions) (Card, error) {
+	data, err := ReadDump(r)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read dump: %w", err)
+	}
+
+	format := opts.Format
+	if format == "" || format == FormatAuto {
+		format = DetectFormat(data)
+		opts.debug("detected input format", "format", format, "bytes", len(data))
+	}
+
+	br := bytes.NewReader(data)
+	switch format {
+	case FormatProxmark3JSON:
+		return parseProxmark3JSONDump(data, opts)
+	case FormatEML:
+		return ParseEML(br, opts)
+	case FormatBin:
+		return ParseBin(br, opts)
+	case FormatMCT:
+		return ParseMCT(br, opts)
+	case FormatFlipperNFC:
+		return ParseFlipperNFC(br, opts)
+	case FormatFlipperRFID:
+		return ParseFlipperRFID(br)
+	case FormatProxmark3Output:
+		return ParseProxmark3MifareOutput(br, opts)
+	case FormatProxmark3LF:
+		return ParseProxmark3LF(br)
+	case FormatUnknown:
+		return nil, ErrUnrecognisedFormat
+	}
+	return nil, fmt.Errorf("unsupported input format '%s'", format)
+}
+
+// Function that parses a Proxmark3 JSON dump with the parser of the card type named by its FileType, EMV scans
+// and Chameleon Ultra GUI exports being told apart by their own headers
+func parseProxmark3JSONDump(data []byte, opts ParseOptions) (Card, error) {
+	var header struct {
+		FileType string `json:"FileType"`
+		Tag      string `json:"tag"`
+		File     struct {
+			Created string `json:"Created"`
+		} `json:"File"`
+	}
+	if err := unmarshalJSON(data, &header); err != nil {
+		return nil, fmt.Errorf("failed to decode Proxmark3 JSON file: %w", err)
+	}
+
+	if isEMVScan(header.File.Created) {
+		opts.debug("JSON dump is an EMV scan", "created", header.File.Created)
+		return ParseProxmark3EMVJSON(bytes.NewReader(data))
+	}
+	if isChameleonCard(header.Tag) {
+		opts.debug("JSON dump is a Chameleon Ultra GUI export", "tag", header.Tag)
+		return ParseChameleonJSON(bytes.NewReader(data), opts)
+	}
+
+	data, modeWarnings, err := applyParseMode(data, opts)
+	if err != nil {
+		return nil, err
+	}
+	if opts.Mode == ParseLenient {
+		if err := unmarshalJSON(data, &header); err != nil {
+			return nil, fmt.Errorf("failed to decode Proxmark3 JSON file: %w", err)
+		}
+	}
+	opts.debug("Proxmark3 JSON dump", "FileType", header.FileType)
+
+	br := bytes.NewReader(data)
+	switch header.FileType {
+	case "felica":
+		opts.warn(modeWarnings)
+		return ParseProxmark3FelicaJSON(br)
+	case "15693":
+		opts.warn(modeWarnings)
+		return ParseProxmark3ISO15693JSON(br)
+	}
+	mc, err := ParseProxmark3JSONWithOptions(br, opts)
+	if err != nil {
+		return nil, err
+	}
+	mc.Warnings = append(modeWarnings, mc.Warnings...)
+	return mc, nil
+}
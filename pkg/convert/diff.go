@@ -0,0 +1,70 @@
+package convert
+
+// Struct describing a block which differs between two dumps of the same card
+type BlockDiff struct {
+	Block   int   `json:"block"`
+	Sector  int   `json:"sector"`
+	A       Block `json:"a"`
+	B       Block `json:"b"`
+	Changed []int `json:"changed"` // indexes of the bytes which differ
+}
+
+// Struct describing the differences between two Mifare Classic dumps
+type MifareDiff struct {
+	UIDA    HexData     `json:"uid_a"`
+	UIDB    HexData     `json:"uid_b"`
+	BlocksA int         `json:"blocks_a"`
+	BlocksB int         `json:"blocks_b"`
+	Blocks  []BlockDiff `json:"blocks"`
+}
+
+// Function that returns the number of sectors with at least one changed block
+func (d *MifareDiff) ChangedSectors() int {
+	n, last := 0, -1
+	for _, bd := range d.Blocks {
+		if bd.Sector != last {
+			n, last = n+1, bd.Sector
+		}
+	}
+	return n
+}
+
+// Function that compares two Mifare Classic dumps block by block. A byte known in one dump and
+// unknown in the other counts as changed; blocks present in one dump only are compared to unknown data.
+func DiffMifare(a, b *MifareCard) *MifareDiff {
+	d := &MifareDiff{UIDA: a.UID, UIDB: b.UID, BlocksA: len(a.Blocks), BlocksB: len(b.Blocks)}
+
+	n := len(a.Blocks)
+	if len(b.Blocks) > n {
+		n = len(b.Blocks)
+	}
+	for i := 0; i < n; i++ {
+		ba, bb := diffBlockAt(a.Blocks, i), diffBlockAt(b.Blocks, i)
+		var changed []int
+		for j := range ba.Data {
+			ua, ub := ba.IsUnknown(j), bb.IsUnknown(j)
+			if ua != ub || (!ua && ba.Data[j] != bb.Data[j]) {
+				changed = append(changed, j)
+			}
+		}
+		if len(changed) > 0 {
+			d.Blocks = append(d.Blocks, BlockDiff{Block: i, Sector: ClassicBlockSector(i), A: ba, B: bb, Changed: changed})
+		}
+	}
+	return d
+}
+
+// Function that returns block i of a dump, or an unknown block when the dump is shorter
+func diffBlockAt(blocks []Block, i int) Block {
+	if i < len(blocks) && len(blocks[i].Data) == ClassicBlockSize {
+		return blocks[i]
+	}
+	if i < len(blocks) {
+		b := UnknownBlock(ClassicBlockSize)
+		for j := 0; j < len(blocks[i].Data) && j < ClassicBlockSize; j++ {
+			b.Data[j], b.Unknown[j] = blocks[i].Data[j], blocks[i].IsUnknown(j)
+		}
+		return b
+	}
+	return UnknownBlock(ClassicBlockSize)
+}
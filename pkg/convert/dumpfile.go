@@ -0,0 +1,170 @@
+package convert
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Function that parses a Proxmark3 EML file, one block of hex data per line, and returns a MifareCard
+// struct identified by its block 0
+func ParseEML(r io.Reader, opts ParseOptions) (*MifareCard, error) {
+	blocks := make(map[string]string)
+
+	sc := bufio.NewScanner(r)
+	n := 0
+	for lineNum := 1; sc.Scan(); lineNum++ {
+		line := strings.TrimSpace(sc.Text())
+		if line == "" {
+			continue
+		}
+		if !emlLineRe.MatchString(line) {
+			return nil, &PositionError{Line: lineNum, Err: &BlockError{Block: n, Err: fmt.Errorf("expecting %d bytes of hex data, got '%s'", ClassicBlockSize, line)}}
+		}
+		if n >= ClassicMaxBlocks {
+			return nil, &PositionError{Line: lineNum, Err: fmt.Errorf("more than the %d blocks of a Mifare Classic 4K", ClassicMaxBlocks)}
+		}
+		blocks[strconv.Itoa(n)] = strings.ReplaceAll(line, "--", "??")
+		n++
+	}
+	if err := sc.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read EML file: %w", err)
+	}
+	if n == 0 {
+		return nil, fmt.Errorf("EML file holds no blocks")
+	}
+
+	return newMifareCard("", "", "", blocks, opts)
+}
+
+// Regular expressions matching the sector header and the block lines of a MIFARE Classic Tool dump
+var (
+	mctSectorRe = regexp.MustCompile(`^\+Sector:\s*(\d+)$`)
+	mctLineRe   = regexp.MustCompile(`^[0-9A-Fa-f-]{32}$`)
+)
+
+// Function that parses a dump of the MIFARE Classic Tool Android app: every sector starts with a
+// "+Sector: N" line followed by its blocks, unknown bytes written as "--". Sectors the app could not read
+// are left out or hold a message instead of blocks, and become unknown blocks
+func ParseMCT(r io.Reader, opts ParseOptions) (*MifareCard, error) {
+	blocks := make(map[string]string)
+
+	sc := bufio.NewScanner(r)
+	sector, block := -1, 0
+	for line := 1; sc.Scan(); line++ {
+		text := strings.TrimSpace(sc.Text())
+		if text == "" {
+			continue
+		}
+		if m := mctSectorRe.FindStringSubmatch(text); m != nil {
+			sector, _ = strconv.Atoi(m[1])
+			if sector >= ClassicSectorCount(ClassicMaxBlocks) {
+				return nil, &PositionError{Line: line, Err: fmt.Errorf("invalid sector %d", sector)}
+			}
+			block = ClassicSectorFirstBlock(sector)
+			continue
+		}
+		if sector < 0 {
+			return nil, &PositionError{Line: line, Err: fmt.Errorf("expecting a '+Sector: N' header, got '%s'", text)}
+		}
+		if !mctLineRe.MatchString(text) {
+			// the message of a sector the app could not read
+			opts.debug("skipped unreadable MCT sector", "line", line, "sector", sector, "message", text)
+			continue
+		}
+		if block >= ClassicSectorFirstBlock(sector)+ClassicSectorBlocks(sector) {
+			return nil, &PositionError{Line: line, Err: &BlockError{Block: block, Err: fmt.Errorf("sector %d has more than %d blocks", sector, ClassicSectorBlocks(sector))}}
+		}
+		blocks[strconv.Itoa(block)] = strings.ReplaceAll(text, "--", "??")
+		block++
+	}
+	if err := sc.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read MCT file: %w", err)
+	}
+	if len(blocks) == 0 {
+		return nil, fmt.Errorf("MCT file holds no blocks")
+	}
+
+	return newMifareCard("", "", "", blocks, opts)
+}
+
+// Function that writes a Mifare card as a MIFARE Classic Tool dump, sector by sector with unknown bytes as "--"
+func WriteMCT(w io.Writer, c *MifareCard) error {
+	for s := 0; s < ClassicSectorCount(len(c.Blocks)); s++ {
+		if _, err := fmt.Fprintf(w, "+Sector: %d\n", s); err != nil {
+			return err
+		}
+		first := ClassicSectorFirstBlock(s)
+		for i := first; i < first+ClassicSectorBlocks(s); i++ {
+			b := c.Blocks[i]
+			var sb strings.Builder
+			for j, v := range b.Data {
+				if b.IsUnknown(j) {
+					sb.WriteString("--")
+				} else {
+					fmt.Fprintf(&sb, "%02X", v)
+				}
+			}
+			if _, err := fmt.Fprintln(w, sb.String()); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// Function that parses a raw binary Mifare Classic dump and returns a MifareCard struct identified by its block 0
+func ParseBin(r io.Reader, opts ParseOptions) (*MifareCard, error) {
+	data, err := ReadDump(r)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read binary dump: %w", err)
+	}
+	if len(data) == 0 || len(data)%ClassicBlockSize != 0 {
+		return nil, fmt.Errorf("binary dump of %d bytes is not made of %d-byte blocks", len(data), ClassicBlockSize)
+	}
+	if len(data) > ClassicMaxBlocks*ClassicBlockSize {
+		return nil, fmt.Errorf("binary dump of %d bytes is larger than a Mifare Classic 4K", len(data))
+	}
+
+	blocks := make(map[string]string, len(data)/ClassicBlockSize)
+	for i := 0; i < len(data); i += ClassicBlockSize {
+		blocks[strconv.Itoa(i/ClassicBlockSize)] = fmt.Sprintf("%X", data[i:i+ClassicBlockSize])
+	}
+
+	return newMifareCard("", "", "", blocks, opts)
+}
+
+// Function that returns the data of a block with its unknown bytes set to 00
+func knownOrZero(b Block) HexData {
+	data := make(HexData, len(b.Data))
+	for i, v := range b.Data {
+		if !b.IsUnknown(i) {
+			data[i] = v
+		}
+	}
+	return data
+}
+
+// Function that writes a Mifare card as a Proxmark3 EML file loadable with `hf mf eload`, one block per line.
+// Unknown bytes are written as 00 since the emulator memory cannot hold them
+func WriteEML(w io.Writer, c *MifareCard) error {
+	for _, b := range c.Blocks {
+		if _, err := fmt.Fprintf(w, "%X\n", []byte(knownOrZero(b))); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Function that writes a Mifare card as a raw binary dump, unknown bytes written as 00
+func WriteBin(w io.Writer, c *MifareCard) error {
+	for _, b := range c.Blocks {
+		if _, err := w.Write(knownOrZero(b)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,219 @@
+package convert
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Error returned when an EMV scan holds no card number, to be matched with errors.Is
+var ErrNoEMVCardNumber = errors.New("no card number (PAN) found in the EMV scan")
+
+// Warning attached to every bank card, shown to users before they store or carry card data around
+const BankCardWarning = "this is payment card data: only convert your own cards, keep the files private; " +
+	"Flipper can show but not emulate bank cards, and copying someone else's card data is illegal in most countries"
+
+// Struct representing an EMV bank card read by the Proxmark3 `emv scan` command, holding the data
+// the Flipper Bank card device type stores
+type BankCard struct {
+	UID          HexData
+	ATQA         HexData // low byte first, as sent by the card
+	SAK          HexData
+	AID          HexData // identifier of the selected payment application
+	Name         string  // application label, e.g. "VISA CREDIT"
+	Number       HexData // primary account number (PAN) as BCD digits, padded with F
+	ExpYear      byte    // expiry year and month as BCD, e.g. 0x24 and 0x07
+	ExpMonth     byte
+	CountryCode  uint16 // issuer country code as BCD, e.g. 0x0250
+	CurrencyCode uint16 // application currency code as BCD, e.g. 0x0978
+	Holder       string // cardholder name, not stored by Flipper
+	Warnings     []string
+}
+
+// Bank cards are stored by Flipper in .nfc files
+func (c *BankCard) FlipperExt() string {
+	return ".nfc"
+}
+
+// Writing a bank card produces a Flipper NFC file
+func (c *BankCard) writeFlipper(w io.Writer, opts WriteOptions) error {
+	return WriteFlipperBankCard(w, c, opts)
+}
+
+// Function that returns the card number as digits with all but the last four masked, for display
+func (c *BankCard) MaskedNumber() string {
+	digits := strings.TrimRight(fmt.Sprintf("%X", []byte(c.Number)), "F")
+	if len(digits) <= 4 {
+		return digits
+	}
+	return strings.Repeat("*", len(digits)-4) + digits[len(digits)-4:]
+}
+
+// Function that reports whether a JSON file header names the Proxmark3 `emv scan` command as its creator
+func isEMVScan(created string) bool {
+	return strings.HasPrefix(created, "proxmark3") && strings.Contains(created, "emv")
+}
+
+// Function that collects the value of every TLV element saved by the Proxmark3 EMV commands, which store
+// them as objects with "tag" and "value" keys nested anywhere in the file. The first value of a tag wins
+func collectEMVTags(v interface{}, tags map[string]HexData) {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		tag, _ := v["tag"].(string)
+		value, _ := v["value"].(string)
+		if tag != "" && value != "" {
+			tag = strings.ToUpper(strings.TrimPrefix(strings.ToLower(tag), "0x"))
+			if b, err := DecodeHexData(strings.ReplaceAll(value, " ", "")); err == nil {
+				if _, ok := tags[tag]; !ok {
+					tags[tag] = b
+				}
+			}
+		}
+		for _, child := range v {
+			collectEMVTags(child, tags)
+		}
+	case []interface{}:
+		for _, child := range v {
+			collectEMVTags(child, tags)
+		}
+	}
+}
+
+// Function that parses the JSON file saved by the Proxmark3 `emv scan` command
+func ParseProxmark3EMVJSON(r io.Reader) (*BankCard, error) {
+	data, err := ReadDump(r)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read dump: %w", err)
+	}
+	var scan struct {
+		File struct {
+			Created string `json:"Created"`
+		} `json:"File"`
+		Card struct {
+			UID  string `json:"UID"`
+			ATQA string `json:"ATQA"`
+			SAK  string `json:"SAK"`
+		} `json:"Card"`
+		Application struct {
+			AID string `json:"AID"`
+		} `json:"Application"`
+	}
+	if err := unmarshalJSON(data, &scan); err != nil {
+		return nil, fmt.Errorf("failed to decode Proxmark3 JSON file: %w", err)
+	}
+	var root interface{}
+	if err := unmarshalJSON(data, &root); err != nil {
+		return nil, fmt.Errorf("failed to decode Proxmark3 JSON file: %w", err)
+	}
+	if !isEMVScan(scan.File.Created) {
+		return nil, ErrNotProxmark3
+	}
+
+	c := &BankCard{Warnings: []string{BankCardWarning}}
+	for _, f := range []struct {
+		name  string
+		value string
+		dst   *HexData
+	}{
+		{"UID", scan.Card.UID, &c.UID},
+		{"ATQA", scan.Card.ATQA, &c.ATQA},
+		{"SAK", scan.Card.SAK, &c.SAK},
+		{"AID", scan.Application.AID, &c.AID},
+	} {
+		if f.value == "" {
+			continue
+		}
+		b, err := DecodeHexData(strings.ReplaceAll(f.value, " ", ""))
+		if err != nil {
+			return nil, &FieldError{Field: f.name, Err: err}
+		}
+		*f.dst = b
+	}
+	if len(c.ATQA) == 2 {
+		atqa, warning, err := NormalizeATQA(c.ATQA, false)
+		if err != nil {
+			return nil, err
+		}
+		c.ATQA = atqa
+		if warning != "" {
+			c.Warnings = append(c.Warnings, warning)
+		}
+	}
+
+	tags := make(map[string]HexData)
+	collectEMVTags(root, tags)
+
+	if len(c.AID) == 0 {
+		c.AID = tags["4F"]
+	}
+	c.Name = strings.TrimSpace(string(tags["50"]))
+	c.Holder = strings.TrimSpace(strings.ReplaceAll(string(tags["5F20"]), "/", " "))
+	c.Number = tags["5A"]
+	if exp := tags["5F24"]; len(exp) >= 2 {
+		c.ExpYear, c.ExpMonth = exp[0], exp[1]
+	}
+	// track 2 equivalent data: PAN, separator D, expiry YYMM, service code and discretionary data
+	if track2 := fmt.Sprintf("%X", []byte(tags["57"])); track2 != "" {
+		if pan, rest, ok := strings.Cut(track2, "D"); ok {
+			if len(c.Number) == 0 {
+				if len(pan)%2 == 1 {
+					pan += "F"
+				}
+				c.Number, _ = DecodeHexData(pan)
+			}
+			if c.ExpMonth == 0 && len(rest) >= 4 {
+				if exp, err := DecodeHexData(rest[:4]); err == nil {
+					c.ExpYear, c.ExpMonth = exp[0], exp[1]
+				}
+			}
+		}
+	}
+	if cc := tags["5F28"]; len(cc) == 2 {
+		c.CountryCode = uint16(cc[0])<<8 | uint16(cc[1])
+	}
+	if cc := tags["9F42"]; len(cc) == 2 {
+		c.CurrencyCode = uint16(cc[0])<<8 | uint16(cc[1])
+	}
+
+	if len(c.Number) == 0 {
+		return nil, ErrNoEMVCardNumber
+	}
+	return c, nil
+}
+
+// Function that writes a bank card in the Flipper NFC format. The Bank card device type only exists in
+// version 2 of the format, which is written whatever version the options select
+func WriteFlipperBankCard(w io.Writer, c *BankCard, _ WriteOptions) error {
+	if _, err := fmt.Fprintf(w, `Filetype: Flipper NFC device
+Version: 2
+# Nfc device type can be UID, Mifare Ultralight, Mifare Classic, Bank card
+Device type: Bank card
+# UID, ATQA and SAK are common for all formats
+UID: %s
+ATQA: %s
+SAK: %s
+# Bank card specific data
+AID: %s
+Name: %s
+Number: %s
+`, c.UID, flipperATQA(c.ATQA, NFCFormatV2), c.SAK, c.AID, c.Name, c.Number); err != nil {
+		return err
+	}
+	if c.ExpMonth != 0 {
+		if _, err := fmt.Fprintf(w, "Exp data: %s\n", HexData{c.ExpMonth, c.ExpYear}); err != nil {
+			return err
+		}
+	}
+	if c.CountryCode != 0 {
+		if _, err := fmt.Fprintf(w, "Country code: %d\n", c.CountryCode); err != nil {
+			return err
+		}
+	}
+	if c.CurrencyCode != 0 {
+		if _, err := fmt.Fprintf(w, "Currency code: %d\n", c.CurrencyCode); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,157 @@
+package convert
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+)
+
+// Sizes in bytes of the FeliCa identifiers and blocks
+const (
+	FelicaIDmSize   = 8
+	FelicaPMmSize   = 8
+	FelicaBlockSize = 16
+)
+
+// Block numbers of a FeliCa Lite-S card, in the order Flipper stores them: S_PAD0 to S_PAD13 and REG,
+// the system blocks RC to MC, then WCNT, MAC_A, STATE and CRC_CHECK
+var FelicaLiteSBlocks = []int{
+	0x00, 0x01, 0x02, 0x03, 0x04, 0x05, 0x06, 0x07, 0x08, 0x09, 0x0A, 0x0B, 0x0C, 0x0D, 0x0E,
+	0x80, 0x81, 0x82, 0x83, 0x84, 0x85, 0x86, 0x87, 0x88,
+	0x90, 0x91, 0x92,
+	0xA0,
+}
+
+// Struct representing the data structure of a FeliCa Lite-S card
+type FelicaCard struct {
+	IDm    HexData // manufacture ID, used as the UID
+	PMm    HexData // manufacture parameter
+	Blocks []Block // blocks in the order of FelicaLiteSBlocks, unknown when they were not read
+}
+
+// FeliCa cards are stored by Flipper in .nfc files
+func (c *FelicaCard) FlipperExt() string {
+	return ".nfc"
+}
+
+// Writing a FeliCa card produces a Flipper NFC file
+func (c *FelicaCard) writeFlipper(w io.Writer, opts WriteOptions) error {
+	return WriteFlipperFelica(w, c, opts)
+}
+
+// Function that parses a Proxmark3 JSON dump of a FeliCa Lite-S card, its blocks keyed by block number
+func ParseProxmark3FelicaJSON(r io.Reader) (*FelicaCard, error) {
+	var proxmark3JSON struct {
+		Created  string `json:"Created"`
+		FileType string `json:"FileType"`
+		Card     struct {
+			IDm string `json:"IDm"`
+			PMm string `json:"PMm"`
+		} `json:"Card"`
+		Blocks map[string]string `json:"blocks"`
+	}
+
+	if err := decodeJSON(r, &proxmark3JSON); err != nil {
+		return nil, fmt.Errorf("failed to decode Proxmark3 JSON file: %w", err)
+	}
+
+	if proxmark3JSON.Created != "proxmark3" {
+		return nil, ErrNotProxmark3
+	}
+
+	if proxmark3JSON.FileType != "felica" {
+		return nil, ErrUnsupportedFileType
+	}
+
+	idm, err := DecodeHexData(proxmark3JSON.Card.IDm)
+	if err != nil {
+		return nil, &FieldError{Field: "IDm", Err: err}
+	}
+	if len(idm) != FelicaIDmSize {
+		return nil, &FieldError{Field: "IDm", Err: fmt.Errorf("expecting %d bytes, got %d", FelicaIDmSize, len(idm))}
+	}
+	pmm, err := DecodeHexData(proxmark3JSON.Card.PMm)
+	if err != nil {
+		return nil, &FieldError{Field: "PMm", Err: err}
+	}
+	if len(pmm) != FelicaPMmSize {
+		return nil, &FieldError{Field: "PMm", Err: fmt.Errorf("expecting %d bytes, got %d", FelicaPMmSize, len(pmm))}
+	}
+
+	known := make(map[int]bool, len(FelicaLiteSBlocks))
+	for _, n := range FelicaLiteSBlocks {
+		known[n] = true
+	}
+	for blockNumStr := range proxmark3JSON.Blocks {
+		n, err := strconv.Atoi(blockNumStr)
+		if err != nil || !known[n] {
+			return nil, fmt.Errorf("invalid FeliCa Lite-S block number '%s'", blockNumStr)
+		}
+	}
+
+	blocks := make([]Block, len(FelicaLiteSBlocks))
+	for i, n := range FelicaLiteSBlocks {
+		blockData, ok := proxmark3JSON.Blocks[strconv.Itoa(n)]
+		if !ok {
+			blocks[i] = UnknownBlock(FelicaBlockSize)
+			continue
+		}
+		if blocks[i], err = DecodeBlock(blockData); err != nil {
+			return nil, &BlockError{Block: n, Err: err}
+		}
+		if len(blocks[i].Data) != FelicaBlockSize {
+			return nil, &BlockError{Block: n, Err: fmt.Errorf("expecting %d bytes, got %d", FelicaBlockSize, len(blocks[i].Data))}
+		}
+	}
+
+	return &FelicaCard{IDm: idm, PMm: pmm, Blocks: blocks}, nil
+}
+
+// Function that writes a FeliCa card in the Flipper NFC format, which knows FeliCa since version 4.
+// Every block is preceded by its two status flags; blocks that were not read are flagged as failed reads
+func WriteFlipperFelica(w io.Writer, c *FelicaCard, opts WriteOptions) error {
+	version := opts.NFCVersion
+	if version == 0 {
+		version = NFCFormatLatest
+	}
+	if version < NFCFormatV4 {
+		return fmt.Errorf("FeliCa cards need Flipper NFC format version %d or later", NFCFormatV4)
+	}
+
+	read := 0
+	for _, b := range c.Blocks {
+		if b.IsComplete() {
+			read++
+		}
+	}
+
+	if _, err := fmt.Fprintf(w, `Filetype: Flipper NFC device
+Version: %d
+# Device type can be ISO14443-3A, ISO14443-3B, ISO14443-4A, ISO15693-3, FeliCa, NTAG/Ultralight, Mifare Classic, Mifare DESFire, SLIX, ST25TB
+Device type: FeliCa
+# UID is common for all formats
+UID: %s
+# FeliCa specific data
+Data format version: 1
+Manufacture id: %s
+Manufacture parameter: %s
+# Felica Lite blocks
+Blocks total: %d
+Blocks read: %d
+`, version, c.IDm, c.IDm, c.PMm, len(c.Blocks), read); err != nil {
+		return err
+	}
+	for i, b := range c.Blocks {
+		flags := HexData{0x00, 0x00}
+		data := b.Data
+		if !b.IsComplete() {
+			flags = HexData{0xFF, 0xFF}
+			data = make(HexData, FelicaBlockSize)
+		}
+		if _, err := fmt.Fprintf(w, "Block %d: %s %s\n", i, flags, data); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
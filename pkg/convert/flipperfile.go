@@ -0,0 +1,86 @@
+package convert
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// Function that reads the "Key: value" lines of a Flipper file, skipping comments, and checks its Filetype
+func readFlipperFile(r io.Reader, fileType string) (map[string]string, error) {
+	fields := make(map[string]string)
+
+	sc := bufio.NewScanner(r)
+	for lineNum := 1; sc.Scan(); lineNum++ {
+		line := strings.TrimSpace(sc.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		key, value, ok := strings.Cut(line, ":")
+		if !ok {
+			return nil, &PositionError{Line: lineNum, Err: fmt.Errorf("invalid line in Flipper file: '%s'", line)}
+		}
+		fields[strings.TrimSpace(key)] = strings.TrimSpace(value)
+	}
+	if err := sc.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read Flipper file: %w", err)
+	}
+
+	if fields["Filetype"] != fileType {
+		return nil, fmt.Errorf("expecting a '%s' file, got '%s'", fileType, fields["Filetype"])
+	}
+	return fields, nil
+}
+
+// Function that parses a Flipper NFC file of a Mifare Classic card and returns a MifareCard struct
+func ParseFlipperNFC(r io.Reader, opts ParseOptions) (*MifareCard, error) {
+	fields, err := readFlipperFile(r, "Flipper NFC device")
+	if err != nil {
+		return nil, err
+	}
+	if fields["Device type"] != "Mifare Classic" {
+		return nil, fmt.Errorf("%w: Flipper NFC file holds a '%s' device", ErrUnsupportedFileType, fields["Device type"])
+	}
+	version, err := strconv.Atoi(fields["Version"])
+	if err != nil {
+		return nil, fmt.Errorf("invalid Flipper NFC file version '%s'", fields["Version"])
+	}
+
+	blocks := make(map[string]string)
+	for key, value := range fields {
+		if strings.HasPrefix(key, "Block ") {
+			blocks[strings.TrimPrefix(key, "Block ")] = strings.ReplaceAll(value, " ", "")
+		}
+	}
+
+	// turn the ATQA back into the low byte first order of the card model
+	atqa, err := DecodeHexData(strings.ReplaceAll(fields["ATQA"], " ", ""))
+	if err != nil {
+		return nil, &FieldError{Field: "ATQA", Err: err}
+	}
+	atqa = flipperATQA(atqa, version)
+
+	return newMifareCard(
+		strings.ReplaceAll(fields["UID"], " ", ""),
+		fmt.Sprintf("%X", []byte(atqa)),
+		strings.ReplaceAll(fields["SAK"], " ", ""),
+		blocks, opts)
+}
+
+// Function that parses a Flipper RFID key file and returns an LFCard struct
+func ParseFlipperRFID(r io.Reader) (*LFCard, error) {
+	fields, err := readFlipperFile(r, "Flipper RFID key")
+	if err != nil {
+		return nil, err
+	}
+	data, err := DecodeHexData(strings.ReplaceAll(fields["Data"], " ", ""))
+	if err != nil {
+		return nil, &FieldError{Field: "Data", Err: err}
+	}
+	if fields["Key type"] == "" {
+		return nil, &FieldError{Field: "Key type", Err: fmt.Errorf("missing")}
+	}
+	return &LFCard{KeyType: fields["Key type"], Data: data}, nil
+}
@@ -0,0 +1,118 @@
+package convert
+
+import (
+	"bytes"
+	"regexp"
+	"unicode/utf8"
+)
+
+// Formats of the dumps the parsers understand
+type InputFormat string
+
+const (
+	FormatAuto            InputFormat = "auto"       // detect the format from the content
+	FormatProxmark3JSON   InputFormat = "json"       // Proxmark3 JSON dump
+	FormatEML             InputFormat = "eml"        // Proxmark3 emulator memory, one hex block per line
+	FormatBin             InputFormat = "bin"        // raw binary dump
+	FormatMCT             InputFormat = "mct"        // MIFARE Classic Tool dump, "+Sector: N" headers
+	FormatFlipperNFC      InputFormat = "nfc"        // Flipper NFC file
+	FormatFlipperRFID     InputFormat = "rfid"       // Flipper RFID key file
+	FormatProxmark3Output InputFormat = "pm3-output" // Proxmark3 client output with a block table
+	FormatProxmark3LF     InputFormat = "lf"         // Proxmark3 LF reader output
+	FormatUnknown         InputFormat = "unknown"    // returned by DetectFormat for content matching no format
+)
+
+// Input formats accepted by ParseOptions, in the order they are listed to users
+var InputFormats = []InputFormat{
+	FormatAuto, FormatProxmark3JSON, FormatEML, FormatBin, FormatMCT, FormatFlipperNFC,
+	FormatFlipperRFID, FormatProxmark3Output, FormatProxmark3LF,
+}
+
+// Formats the writers can produce
+type OutputFormat string
+
+const (
+	OutputFlipper       OutputFormat = "nfc"       // Flipper file matching the card, .nfc or .rfid
+	OutputProxmark3JSON OutputFormat = "json"      // Proxmark3 JSON dump
+	OutputEML           OutputFormat = "eml"       // Proxmark3 emulator memory, one hex block per line
+	OutputBin           OutputFormat = "bin"       // raw binary dump
+	OutputMCT           OutputFormat = "mct"       // MIFARE Classic Tool dump
+	OutputChameleon     OutputFormat = "chameleon" // Chameleon Ultra GUI card export, a .json file
+)
+
+// Output formats accepted by WriteOptions, in the order they are listed to users
+var OutputFormats = []OutputFormat{OutputFlipper, OutputProxmark3JSON, OutputEML, OutputBin, OutputMCT, OutputChameleon}
+
+// Function that returns the extension of the files holding a card in the given output format
+func OutputExt(c Card, format OutputFormat) string {
+	switch format {
+	case "", OutputFlipper:
+		return c.FlipperExt()
+	case OutputChameleon:
+		return ".json"
+	}
+	return "." + string(format)
+}
+
+// Regular expression matching a line of an EML file
+var emlLineRe = regexp.MustCompile(`^[0-9A-Fa-f?-]{32}$`)
+
+// Function that detects the format of a dump from its content: the Flipper header, a JSON object, the
+// sector headers of MCT files, the line structure of EML files, the length of binary dumps, the block table
+// of client output or the lines of the LF reader commands. FormatUnknown is returned when none of them match
+func DetectFormat(data []byte) InputFormat {
+	trimmed := bytes.TrimSpace(data)
+	switch {
+	case bytes.HasPrefix(trimmed, []byte("Filetype: Flipper NFC device")):
+		return FormatFlipperNFC
+	case bytes.HasPrefix(trimmed, []byte("Filetype: Flipper RFID key")):
+		return FormatFlipperRFID
+	case bytes.HasPrefix(trimmed, []byte("{")):
+		return FormatProxmark3JSON
+	case bytes.HasPrefix(trimmed, []byte("+Sector:")):
+		return FormatMCT
+	}
+
+	if !isText(data) {
+		return FormatBin
+	}
+
+	lines := bytes.Split(trimmed, []byte("\n"))
+	eml := len(trimmed) > 0
+	for _, line := range lines {
+		if !emlLineRe.Match(bytes.TrimSpace(line)) {
+			eml = false
+			break
+		}
+	}
+	if eml {
+		return FormatEML
+	}
+
+	for _, line := range lines {
+		if outputBlockRe.Match(ansiEscapeRe.ReplaceAll(line, nil)) {
+			return FormatProxmark3Output
+		}
+	}
+
+	for _, line := range lines {
+		if lfOutputRe.Match(ansiEscapeRe.ReplaceAll(line, nil)) {
+			return FormatProxmark3LF
+		}
+	}
+	return FormatUnknown
+}
+
+// Function that reports whether data looks like text: valid UTF-8 without control characters
+// other than whitespace and the escape sequences of colored terminal output
+func isText(data []byte) bool {
+	if !utf8.Valid(data) {
+		return false
+	}
+	for _, b := range data {
+		if b < 0x20 && b != '\n' && b != '\r' && b != '\t' && b != 0x1b {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,43 @@
+package convert
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestDetectFormat(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+		want InputFormat
+	}{
+		{"Flipper NFC", "Filetype: Flipper NFC device\nVersion: 4\n", FormatFlipperNFC},
+		{"Flipper RFID", "Filetype: Flipper RFID key\nVersion: 1\n", FormatFlipperRFID},
+		{"JSON", `{"Created": "proxmark3"}`, FormatProxmark3JSON},
+		{"MCT", "+Sector: 0\n", FormatMCT},
+		{"EML", strings.Repeat("00112233445566778899AABBCCDDEEFF\n", 4), FormatEML},
+		{"binary", "\x11\x22\x33\x44\x44\x08\x04\x00", FormatBin},
+		{"client output", "[=]   0 | 11 22 33 44 44 08 04 00 62 63 64 65 66 67 68 69 | .\"3DD...bcdefghi\n", FormatProxmark3Output},
+		{"EM410x reader", em410xReaderOutput, FormatProxmark3LF},
+		{"HID reader", hidReaderOutput, FormatProxmark3LF},
+		{"Indala reader", indalaReaderOutput, FormatProxmark3LF},
+		{"LF search without tag", "[usb] pm3 --> lf search\n[-] No known 125/134 kHz tags found!\n", FormatProxmark3LF},
+		{"plain text", "shopping list\nmilk\neggs\n", FormatUnknown},
+		{"empty", "", FormatUnknown},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := DetectFormat([]byte(tt.data)); got != tt.want {
+				t.Errorf("DetectFormat = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseUnrecognisedFormat(t *testing.T) {
+	_, err := Parse(strings.NewReader("shopping list\nmilk\neggs\n"))
+	if !errors.Is(err, ErrUnrecognisedFormat) {
+		t.Errorf("Parse error = %v, want %v", err, ErrUnrecognisedFormat)
+	}
+}
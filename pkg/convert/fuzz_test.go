@@ -0,0 +1,181 @@
+package convert
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// Function that seeds a fuzz target with dumps of testdata/ and a few inputs found at the edges of the parsers
+func addSeeds(f *testing.F, files ...string) {
+	f.Helper()
+	for _, name := range files {
+		data, err := os.ReadFile(filepath.Join("testdata", name))
+		if err != nil {
+			f.Fatalf("cannot read seed: %v", err)
+		}
+		f.Add(data)
+	}
+	for _, s := range []string{"", "\n", "{}", "??", "0\n", "\x00\xff"} {
+		f.Add([]byte(s))
+	}
+}
+
+// Function that checks that two Mifare Classic cards hold the same identification and blocks. With zeroUnknown
+// set, unknown bytes of want are compared as 00, for the formats which cannot tell them apart
+func checkSameMifare(t *testing.T, got, want *MifareCard, zeroUnknown bool) {
+	t.Helper()
+	if !bytes.Equal(got.UID, want.UID) || !bytes.Equal(got.ATQA, want.ATQA) || !bytes.Equal(got.SAK, want.SAK) {
+		t.Fatalf("identification changed: UID %s ATQA %s SAK %s, want UID %s ATQA %s SAK %s",
+			got.UID, got.ATQA, got.SAK, want.UID, want.ATQA, want.SAK)
+	}
+	if len(got.Blocks) != len(want.Blocks) {
+		t.Fatalf("got %d blocks, want %d", len(got.Blocks), len(want.Blocks))
+	}
+	for i := range want.Blocks {
+		w := want.Blocks[i]
+		if zeroUnknown {
+			w = Block{Data: knownOrZero(w)}
+		}
+		if got.Blocks[i].String() != w.String() {
+			t.Fatalf("block %d changed: %s, want %s", i, got.Blocks[i], w)
+		}
+	}
+}
+
+// Function that fuzzes a Mifare Classic parser: the parser must not panic, and a card it accepts must come
+// back unchanged once written with write and parsed again
+func fuzzMifareRoundTrip(f *testing.F, parse func([]byte) (*MifareCard, error), write func(*bytes.Buffer, *MifareCard) error,
+	parseAgain func([]byte) (*MifareCard, error), zeroUnknown bool) {
+	f.Fuzz(func(t *testing.T, data []byte) {
+		card, err := parse(data)
+		if err != nil {
+			return
+		}
+		var buf bytes.Buffer
+		if err := write(&buf, card); err != nil {
+			t.Fatalf("cannot write parsed card: %v", err)
+		}
+		again, err := parseAgain(buf.Bytes())
+		if err != nil {
+			t.Fatalf("cannot parse written card: %v\n%s", err, buf.Bytes())
+		}
+		checkSameMifare(t, again, card, zeroUnknown)
+	})
+}
+
+func FuzzParseProxmark3JSON(f *testing.F) {
+	addSeeds(f, "hf-mf-11223344-dump.json")
+	parse := func(data []byte) (*MifareCard, error) {
+		// the parse modes only have to survive the input, the round trip is checked on the default mode
+		for _, mode := range []ParseMode{ParseStrict, ParseLenient} {
+			_, _ = ParseWithOptions(bytes.NewReader(data), ParseOptions{Format: FormatProxmark3JSON, Mode: mode})
+		}
+		return ParseProxmark3JSON(bytes.NewReader(data))
+	}
+	fuzzMifareRoundTrip(f, parse,
+		func(buf *bytes.Buffer, c *MifareCard) error { return WriteProxmark3JSON(buf, c) },
+		func(data []byte) (*MifareCard, error) { return ParseProxmark3JSON(bytes.NewReader(data)) },
+		false)
+}
+
+func FuzzParseEML(f *testing.F) {
+	addSeeds(f, "hf-mf-11223344-dump.eml")
+	parse := func(data []byte) (*MifareCard, error) { return ParseEML(bytes.NewReader(data), ParseOptions{}) }
+	fuzzMifareRoundTrip(f, parse, func(buf *bytes.Buffer, c *MifareCard) error { return WriteEML(buf, c) }, parse, true)
+}
+
+func FuzzParseBin(f *testing.F) {
+	addSeeds(f, "hf-mf-11223344-dump.bin")
+	parse := func(data []byte) (*MifareCard, error) { return ParseBin(bytes.NewReader(data), ParseOptions{}) }
+	fuzzMifareRoundTrip(f, parse, func(buf *bytes.Buffer, c *MifareCard) error { return WriteBin(buf, c) }, parse, true)
+}
+
+func FuzzParseMCT(f *testing.F) {
+	addSeeds(f, "hf-mf-11223344-dump.mct")
+	parse := func(data []byte) (*MifareCard, error) { return ParseMCT(bytes.NewReader(data), ParseOptions{}) }
+	fuzzMifareRoundTrip(f, parse, func(buf *bytes.Buffer, c *MifareCard) error { return WriteMCT(buf, c) }, parse, false)
+}
+
+func FuzzParseFlipperNFC(f *testing.F) {
+	addSeeds(f, "hf-mf-11223344.nfc")
+	parse := func(data []byte) (*MifareCard, error) { return ParseFlipperNFC(bytes.NewReader(data), ParseOptions{}) }
+	fuzzMifareRoundTrip(f, parse, func(buf *bytes.Buffer, c *MifareCard) error { return WriteFlipperNFC(buf, c) }, parse, false)
+}
+
+func FuzzParseProxmark3MifareOutput(f *testing.F) {
+	addSeeds(f, "hf-mf-eview-output.txt")
+	// client output has no writer, so the card goes through a Flipper NFC file
+	fuzzMifareRoundTrip(f,
+		func(data []byte) (*MifareCard, error) {
+			return ParseProxmark3MifareOutput(bytes.NewReader(data), ParseOptions{})
+		},
+		func(buf *bytes.Buffer, c *MifareCard) error { return WriteFlipperNFC(buf, c) },
+		func(data []byte) (*MifareCard, error) { return ParseFlipperNFC(bytes.NewReader(data), ParseOptions{}) },
+		false)
+}
+
+func FuzzParseChameleonJSON(f *testing.F) {
+	addSeeds(f, "chameleon-mifare1k.json")
+	parse := func(data []byte) (*MifareCard, error) {
+		return ParseChameleonJSON(bytes.NewReader(data), ParseOptions{})
+	}
+	fuzzMifareRoundTrip(f, parse, func(buf *bytes.Buffer, c *MifareCard) error { return WriteChameleonJSON(buf, c) }, parse, true)
+}
+
+// Function that fuzzes an LF key parser: the parser must not panic, and a key it accepts must come back
+// unchanged from a Flipper RFID file
+func fuzzLFRoundTrip(f *testing.F, parse func([]byte) (*LFCard, error)) {
+	f.Fuzz(func(t *testing.T, data []byte) {
+		card, err := parse(data)
+		if err != nil {
+			return
+		}
+		var buf bytes.Buffer
+		if err := WriteFlipperRFID(&buf, card); err != nil {
+			t.Fatalf("cannot write parsed key: %v", err)
+		}
+		again, err := ParseFlipperRFID(&buf)
+		if err != nil {
+			t.Fatalf("cannot parse written key: %v", err)
+		}
+		if again.KeyType != card.KeyType || !bytes.Equal(again.Data, card.Data) {
+			t.Fatalf("key changed: %s %s, want %s %s", again.KeyType, again.Data, card.KeyType, card.Data)
+		}
+	})
+}
+
+func FuzzParseFlipperRFID(f *testing.F) {
+	addSeeds(f, "em4100.rfid")
+	fuzzLFRoundTrip(f, func(data []byte) (*LFCard, error) { return ParseFlipperRFID(bytes.NewReader(data)) })
+}
+
+func FuzzParseProxmark3LF(f *testing.F) {
+	addSeeds(f, "lf-em410x-reader.txt", "lf-hid-reader.txt", "lf-indala-reader.txt")
+	fuzzLFRoundTrip(f, func(data []byte) (*LFCard, error) { return ParseProxmark3LF(bytes.NewReader(data)) })
+}
+
+func FuzzParse(f *testing.F) {
+	entries, err := os.ReadDir("testdata")
+	if err != nil {
+		f.Fatal(err)
+	}
+	var files []string
+	for _, e := range entries {
+		if e.Type().IsRegular() {
+			files = append(files, e.Name())
+		}
+	}
+	addSeeds(f, files...)
+	f.Fuzz(func(t *testing.T, data []byte) {
+		card, err := Parse(bytes.NewReader(data))
+		if err != nil {
+			return
+		}
+		// whatever the detected format, the card must be writable as a Flipper file
+		if err := WriteFlipper(&bytes.Buffer{}, card); err != nil {
+			t.Fatalf("cannot write parsed %T: %v", card, err)
+		}
+	})
+}
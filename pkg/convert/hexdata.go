@@ -0,0 +1,39 @@
+package convert
+
+import (
+	"encoding/hex"
+	"fmt"
+	"strings"
+)
+
+// Type for a slice of bytes, which is used to represent hexadecimal data
+type HexData []byte
+
+// String method for HexData type to print hexadecimal data
+func (h HexData) String() string {
+	var sb strings.Builder
+
+	n := len(h)
+	for i := 0; i < n-1; i++ {
+		sb.WriteString(fmt.Sprintf("%02X ", h[i]))
+	}
+	if n >= 1 {
+		sb.WriteString(fmt.Sprintf("%02X", h[n-1]))
+	}
+
+	return sb.String()
+}
+
+// MarshalText method for HexData type to encode hexadecimal data the way String prints it
+func (h HexData) MarshalText() ([]byte, error) {
+	return []byte(h.String()), nil
+}
+
+// Function that decodes hexadecimal data from a string and returns it as a HexData type
+func DecodeHexData(hexStr string) (bs HexData, err error) {
+	bs, err = hex.DecodeString(hexStr)
+	if err != nil {
+		err = fmt.Errorf("failed to parse hex data '%s': %w", hexStr, err)
+	}
+	return
+}
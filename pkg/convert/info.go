@@ -0,0 +1,118 @@
+package convert
+
+import "fmt"
+
+// IC manufacturers by their ISO/IEC 7816-6 code, found in the first byte of 7-byte ISO 14443 UIDs
+// and in the second byte of ISO 15693 UIDs
+var icManufacturers = map[byte]string{
+	0x01: "Motorola",
+	0x02: "STMicroelectronics",
+	0x03: "Hitachi",
+	0x04: "NXP Semiconductors",
+	0x05: "Infineon Technologies",
+	0x06: "Cylink",
+	0x07: "Texas Instruments",
+	0x08: "Fujitsu",
+	0x09: "Matsushita",
+	0x0A: "NEC",
+	0x0B: "Oki Electric",
+	0x0C: "Toshiba",
+	0x0D: "Mitsubishi Electric",
+	0x0E: "Samsung Electronics",
+	0x0F: "Hynix",
+	0x10: "LG Semiconductors",
+	0x11: "Emosyn-EM Microelectronics",
+	0x12: "INSIDE Technology",
+	0x13: "ORGA Kartensysteme",
+	0x14: "Sharp",
+	0x15: "Atmel",
+	0x16: "EM Microelectronic-Marin",
+	0x17: "SMARTRAC",
+	0x19: "ZMD",
+	0x1A: "XICOR",
+	0x1B: "Sony",
+	0x1C: "Malaysia Microelectronic Solutions",
+	0x1D: "Emosyn",
+	0x1E: "Shanghai Fudan Microelectronics",
+	0x1F: "Magellan Technology",
+	0x20: "Melexis",
+	0x21: "Renesas Technology",
+	0x22: "TAGSYS",
+	0x23: "Transcore",
+	0x24: "Shanghai Belling",
+	0x25: "Masktech",
+	0x26: "Innovision Research and Technology",
+	0x27: "Hitachi ULSI Systems",
+	0x28: "Yubico",
+	0x29: "Ricoh",
+	0x2A: "ASK",
+	0x2B: "Unicore Microsystems",
+	0x2C: "Dallas Semiconductor/Maxim",
+}
+
+// Function that describes the IC manufacturer of a UID: the manufacturer code of 7-byte ISO 14443 and
+// ISO 15693 UIDs, or the kind of ID of 4-byte UIDs, which carry no manufacturer
+func UIDManufacturer(uid HexData) string {
+	var code byte
+	switch {
+	case len(uid) == 4 && uid[0] == 0x08:
+		return "none, random ID (RID) generated at every activation"
+	case len(uid) == 4 && uid[0]&0x0F == 0x0F:
+		return "none, non-unique ID (NUID)"
+	case len(uid) == 4:
+		return "none, single size UID"
+	case len(uid) == 7 || len(uid) == 10:
+		code = uid[0]
+	case len(uid) == ISO15693UIDSize && uid[0] == 0xE0:
+		code = uid[1]
+	default:
+		return "unknown"
+	}
+	if name, ok := icManufacturers[code]; ok {
+		return name
+	}
+	return fmt.Sprintf("unknown (code %02X)", code)
+}
+
+// Function that guesses the chip of an ISO 14443-A card from its ATQA (low byte first) and SAK,
+// following the NXP card identification tables
+func ChipFromATQASAK(atqa, sak HexData) string {
+	if len(atqa) != 2 || len(sak) != 1 {
+		return "unknown"
+	}
+	switch sak[0] {
+	case 0x00:
+		return "MIFARE Ultralight or NTAG"
+	case 0x01:
+		return "TNP3xxx (MIFARE Classic 1K protocol)"
+	case 0x08:
+		if atqa[0]&0x40 != 0 {
+			return "MIFARE Classic 1K with 7-byte UID, or MIFARE Plus 2K/4K in SL1"
+		}
+		return "MIFARE Classic 1K, or MIFARE Plus 2K/4K in SL1"
+	case 0x09:
+		return "MIFARE Mini"
+	case 0x10:
+		return "MIFARE Plus 2K in SL2"
+	case 0x11:
+		return "MIFARE Plus 4K in SL2"
+	case 0x18:
+		if atqa[0]&0x40 != 0 {
+			return "MIFARE Classic 4K with 7-byte UID, or MIFARE Plus 4K in SL1"
+		}
+		return "MIFARE Classic 4K, or MIFARE Plus 4K in SL1"
+	case 0x19:
+		return "MIFARE Classic 2K"
+	case 0x20:
+		return "ISO 14443-4 card (MIFARE DESFire, MIFARE Plus in SL3, smart card)"
+	case 0x28:
+		return "smart card emulating MIFARE Classic 1K (e.g. JCOP)"
+	case 0x38:
+		return "smart card emulating MIFARE Classic 4K (e.g. JCOP)"
+	case 0x88:
+		return "Infineon MIFARE Classic 1K"
+	case 0x98:
+		return "Gemplus MPCOS"
+	}
+	return "unknown"
+}
@@ -0,0 +1,300 @@
+package convert
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// Size in bytes of an ISO 15693 UID
+const ISO15693UIDSize = 8
+
+// Struct representing the SLIX passwords and signature found in a dump, empty when unknown
+type SLIXData struct {
+	PasswordRead    HexData
+	PasswordWrite   HexData
+	PasswordPrivacy HexData
+	PasswordDestroy HexData
+	PasswordEAS     HexData
+	Signature       HexData
+}
+
+// Struct representing the data structure of an ISO 15693 (NFC-V) card
+type ISO15693Card struct {
+	UID       HexData // most significant byte (E0) first
+	DSFID     byte
+	AFI       byte
+	IC        byte // IC reference
+	BlockSize int
+	Blocks    []Block
+	Locked    []bool // lock bit of every block
+	SLIX      SLIXData
+}
+
+// ISO 15693 cards are stored by Flipper in .nfc files
+func (c *ISO15693Card) FlipperExt() string {
+	return ".nfc"
+}
+
+// Writing an ISO 15693 card produces a Flipper NFC file
+func (c *ISO15693Card) writeFlipper(w io.Writer, opts WriteOptions) error {
+	return WriteFlipperISO15693(w, c, opts)
+}
+
+// Function that reports whether the card is an NXP ICODE SLIX, which Flipper stores with the SLIX fields
+func (c *ISO15693Card) IsSLIX() bool {
+	return len(c.UID) == ISO15693UIDSize && c.UID[1] == 0x04 && c.UID[2] == 0x01
+}
+
+// Function that parses a Proxmark3 JSON dump of an ISO 15693 card (`hf 15693 dump`)
+func ParseProxmark3ISO15693JSON(r io.Reader) (*ISO15693Card, error) {
+	var proxmark3JSON struct {
+		Created  string `json:"Created"`
+		FileType string `json:"FileType"`
+		Card     struct {
+			UID             string `json:"UID"`
+			DSFID           string `json:"DSFID"`
+			AFI             string `json:"AFI"`
+			IC              string `json:"IC"`
+			BlockSize       string `json:"BlockSize"`
+			Locks           string `json:"Locks"`
+			PasswordRead    string `json:"PasswordRead"`
+			PasswordWrite   string `json:"PasswordWrite"`
+			PasswordPrivacy string `json:"PasswordPrivacy"`
+			PasswordDestroy string `json:"PasswordDestroy"`
+			PasswordEAS     string `json:"PasswordEAS"`
+			Signature       string `json:"Signature"`
+		} `json:"Card"`
+		Blocks map[string]string `json:"blocks"`
+	}
+
+	if err := decodeJSON(r, &proxmark3JSON); err != nil {
+		return nil, fmt.Errorf("failed to decode Proxmark3 JSON file: %w", err)
+	}
+
+	if proxmark3JSON.Created != "proxmark3" {
+		return nil, ErrNotProxmark3
+	}
+
+	if proxmark3JSON.FileType != "15693" {
+		return nil, ErrUnsupportedFileType
+	}
+
+	card := &proxmark3JSON.Card
+	uid, err := DecodeHexData(card.UID)
+	if err != nil {
+		return nil, &FieldError{Field: "UID", Err: err}
+	}
+	if len(uid) != ISO15693UIDSize {
+		return nil, &FieldError{Field: "UID", Err: fmt.Errorf("expecting %d bytes, got %d", ISO15693UIDSize, len(uid))}
+	}
+	// the client may store the UID in transmission order, least significant byte first
+	if uid[0] != 0xE0 && uid[ISO15693UIDSize-1] == 0xE0 {
+		for i, j := 0, len(uid)-1; i < j; i, j = i+1, j-1 {
+			uid[i], uid[j] = uid[j], uid[i]
+		}
+	}
+
+	c := &ISO15693Card{UID: uid}
+	for _, f := range []struct {
+		name  string
+		value string
+		dst   *byte
+	}{
+		{"DSFID", card.DSFID, &c.DSFID},
+		{"AFI", card.AFI, &c.AFI},
+		{"IC", card.IC, &c.IC},
+	} {
+		if f.value == "" {
+			continue
+		}
+		b, err := DecodeHexData(f.value)
+		if err != nil {
+			return nil, &FieldError{Field: f.name, Err: err}
+		}
+		if len(b) != 1 {
+			return nil, &FieldError{Field: f.name, Err: fmt.Errorf("expecting 1 byte, got %d", len(b))}
+		}
+		*f.dst = b[0]
+	}
+
+	for _, f := range []struct {
+		name  string
+		value string
+		size  int
+		dst   *HexData
+	}{
+		{"PasswordRead", card.PasswordRead, 4, &c.SLIX.PasswordRead},
+		{"PasswordWrite", card.PasswordWrite, 4, &c.SLIX.PasswordWrite},
+		{"PasswordPrivacy", card.PasswordPrivacy, 4, &c.SLIX.PasswordPrivacy},
+		{"PasswordDestroy", card.PasswordDestroy, 4, &c.SLIX.PasswordDestroy},
+		{"PasswordEAS", card.PasswordEAS, 4, &c.SLIX.PasswordEAS},
+		{"Signature", card.Signature, 32, &c.SLIX.Signature},
+	} {
+		if f.value == "" {
+			continue
+		}
+		b, err := DecodeHexData(f.value)
+		if err != nil {
+			return nil, &FieldError{Field: f.name, Err: err}
+		}
+		if len(b) != f.size {
+			return nil, &FieldError{Field: f.name, Err: fmt.Errorf("expecting %d bytes, got %d", f.size, len(b))}
+		}
+		*f.dst = b
+	}
+
+	maxBlock := -1
+	for blockNumStr := range proxmark3JSON.Blocks {
+		n, err := strconv.Atoi(blockNumStr)
+		if err != nil || n < 0 || n > 255 {
+			return nil, fmt.Errorf("invalid block number '%s'", blockNumStr)
+		}
+		if n > maxBlock {
+			maxBlock = n
+		}
+	}
+	if maxBlock < 0 {
+		return nil, fmt.Errorf("no ISO 15693 blocks found in the dump")
+	}
+
+	c.Blocks = make([]Block, maxBlock+1)
+	for i := range c.Blocks {
+		blockData, ok := proxmark3JSON.Blocks[strconv.Itoa(i)]
+		if !ok {
+			continue
+		}
+		if c.Blocks[i], err = DecodeBlock(blockData); err != nil {
+			return nil, &BlockError{Block: i, Err: err}
+		}
+		if c.BlockSize == 0 {
+			c.BlockSize = len(c.Blocks[i].Data)
+		} else if len(c.Blocks[i].Data) != c.BlockSize {
+			return nil, &BlockError{Block: i, Err: fmt.Errorf("expecting %d bytes like the other blocks, got %d", c.BlockSize, len(c.Blocks[i].Data))}
+		}
+	}
+	if card.BlockSize != "" {
+		size, err := strconv.ParseInt(card.BlockSize, 0, 0)
+		if err != nil || size < 1 || size > 32 {
+			return nil, &FieldError{Field: "BlockSize", Err: fmt.Errorf("invalid block size '%s'", card.BlockSize)}
+		}
+		if int(size) != c.BlockSize && c.BlockSize != 0 {
+			return nil, &FieldError{Field: "BlockSize", Err: fmt.Errorf("%d does not match the %d bytes of the blocks", size, c.BlockSize)}
+		}
+		c.BlockSize = int(size)
+	}
+	for i := range c.Blocks {
+		if c.Blocks[i].Data == nil {
+			c.Blocks[i] = UnknownBlock(c.BlockSize)
+		}
+	}
+
+	c.Locked = make([]bool, len(c.Blocks))
+	if card.Locks != "" {
+		locks, err := DecodeHexData(card.Locks)
+		if err != nil {
+			return nil, &FieldError{Field: "Locks", Err: err}
+		}
+		for i := range c.Locked {
+			c.Locked[i] = i < len(locks) && locks[i] != 0
+		}
+	}
+
+	return c, nil
+}
+
+// Function that writes an ISO 15693 card in the Flipper NFC format, which knows ISO 15693 since version 4.
+// NXP ICODE SLIX cards get the SLIX section with the passwords found in the dump; unknown bytes are written as 00
+// since Flipper cannot emulate them otherwise
+func WriteFlipperISO15693(w io.Writer, c *ISO15693Card, opts WriteOptions) error {
+	version := opts.NFCVersion
+	if version == 0 {
+		version = NFCFormatLatest
+	}
+	if version < NFCFormatV4 {
+		return fmt.Errorf("ISO 15693 cards need Flipper NFC format version %d or later", NFCFormatV4)
+	}
+
+	deviceType := "ISO15693-3"
+	if c.IsSLIX() {
+		deviceType = "SLIX"
+	}
+
+	content := make(HexData, 0, len(c.Blocks)*c.BlockSize)
+	security := make(HexData, len(c.Blocks))
+	for i, b := range c.Blocks {
+		for j := 0; j < c.BlockSize; j++ {
+			if j < len(b.Data) && !b.IsUnknown(j) {
+				content = append(content, b.Data[j])
+			} else {
+				content = append(content, 0)
+			}
+		}
+		if i < len(c.Locked) && c.Locked[i] {
+			security[i] = 1
+		}
+	}
+
+	if _, err := fmt.Fprintf(w, `Filetype: Flipper NFC device
+Version: %d
+# Device type can be ISO14443-3A, ISO14443-3B, ISO14443-4A, ISO15693-3, FeliCa, NTAG/Ultralight, Mifare Classic, Mifare DESFire, SLIX, ST25TB
+Device type: %s
+# UID is common for all formats
+UID: %s
+# ISO15693-3 specific data
+Data Format Version: 1
+# Lock Info
+DSFID: %02X
+AFI: %02X
+IC Reference: %02X
+Lock DSFID: false
+Lock AFI: false
+# Number of memory blocks, valid range = 1..256
+Block Count: %d
+# Size of a single memory block, valid range = 01...20 (hex)
+Block Size: %02X
+Data Content: %s
+# Block Security Status: 01 = locked, 00 = not locked
+Security Status: %s
+`, version, deviceType, c.UID, c.DSFID, c.AFI, c.IC, len(c.Blocks), c.BlockSize, content, security); err != nil {
+		return err
+	}
+	if !c.IsSLIX() {
+		return nil
+	}
+
+	var sb strings.Builder
+	sb.WriteString("# SLIX specific data\n# Passwords are optional. If a password is omitted, a default value will be used\n")
+	for _, p := range []struct {
+		name  string
+		value HexData
+	}{
+		{"Password Read", c.SLIX.PasswordRead},
+		{"Password Write", c.SLIX.PasswordWrite},
+		{"Password Privacy", c.SLIX.PasswordPrivacy},
+		{"Password Destroy", c.SLIX.PasswordDestroy},
+		{"Password EAS", c.SLIX.PasswordEAS},
+	} {
+		if len(p.value) > 0 {
+			fmt.Fprintf(&sb, "%s: %s\n", p.name, p.value)
+		}
+	}
+	signature := c.SLIX.Signature
+	if len(signature) == 0 {
+		signature = make(HexData, 32)
+	}
+	fmt.Fprintf(&sb, `# This is the card's secp128r1 elliptic curve signature. It can not be calculated without knowing NXP's private key.
+Signature: %s
+Privacy Mode: false
+# Protection pointer configuration
+Protection Pointer: 00
+Protection Condition: 02
+# SLIX Lock Bits
+Lock EAS: false
+Lock PPL: false
+`, signature)
+
+	_, err := io.WriteString(w, sb.String())
+	return err
+}
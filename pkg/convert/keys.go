@@ -0,0 +1,162 @@
+package convert
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+)
+
+// Size in bytes of a Mifare Classic key
+const ClassicKeySize = 6
+
+// Struct holding the keys stored in a Mifare Classic sector trailer, nil when the key bytes are unknown
+type SectorKeys struct {
+	Sector int
+	KeyA   HexData
+	KeyB   HexData
+}
+
+// Function that returns the keys of every sector trailer of the card
+func (c *MifareCard) SectorKeys() []SectorKeys {
+	sectors := ClassicSectorCount(len(c.Blocks))
+	keys := make([]SectorKeys, 0, sectors)
+	for s := 0; s < sectors; s++ {
+		trailer := c.Blocks[ClassicSectorTrailer(s)]
+		keys = append(keys, SectorKeys{
+			Sector: s,
+			KeyA:   knownBytes(trailer, 0, ClassicKeySize),
+			KeyB:   knownBytes(trailer, 10, ClassicKeySize),
+		})
+	}
+	return keys
+}
+
+// Function that returns the unique known keys of the card, Key A and Key B alike, in order of appearance
+func (c *MifareCard) UniqueKeys() []HexData {
+	var keys []HexData
+	for _, sk := range c.SectorKeys() {
+		keys = AppendUniqueKeys(keys, sk.KeyA, sk.KeyB)
+	}
+	return keys
+}
+
+// Function that appends to keys the given ones which are not nil and not in keys yet
+func AppendUniqueKeys(keys []HexData, more ...HexData) []HexData {
+	for _, k := range more {
+		if k == nil {
+			continue
+		}
+		dup := false
+		for _, have := range keys {
+			if bytes.Equal(have, k) {
+				dup = true
+				break
+			}
+		}
+		if !dup {
+			keys = append(keys, k)
+		}
+	}
+	return keys
+}
+
+// Function that returns n bytes of a block starting at offset, or nil when any of them is unknown
+func knownBytes(b Block, offset, n int) HexData {
+	if len(b.Data) < offset+n {
+		return nil
+	}
+	for i := offset; i < offset+n; i++ {
+		if b.IsUnknown(i) {
+			return nil
+		}
+	}
+	return b.Data[offset : offset+n]
+}
+
+// Formats of the key dictionaries the writer can produce
+type DictFormat string
+
+const (
+	DictProxmark3 DictFormat = "pm3"     // Proxmark3 .dic file
+	DictFlipper   DictFormat = "flipper" // Flipper mf_classic_dict_user.nfc
+)
+
+// Function that writes keys to a writer as a key dictionary, one key per line
+func WriteKeysDict(w io.Writer, keys []HexData, format DictFormat) error {
+	switch format {
+	case DictProxmark3:
+		if _, err := fmt.Fprintln(w, "# Mifare Classic keys extracted by proxmark3-to-flipper"); err != nil {
+			return err
+		}
+		for _, k := range keys {
+			if _, err := fmt.Fprintf(w, "%x\n", []byte(k)); err != nil {
+				return err
+			}
+		}
+	case DictFlipper:
+		for _, k := range keys {
+			if _, err := fmt.Fprintf(w, "%X\n", []byte(k)); err != nil {
+				return err
+			}
+		}
+	default:
+		return fmt.Errorf("unsupported key dictionary format '%s'", format)
+	}
+	return nil
+}
+
+// Function that returns the name of the Flipper key cache file of the card, its UID in hex
+func (c *MifareCard) KeyCacheName() string {
+	return fmt.Sprintf("%X.keys", []byte(c.UID))
+}
+
+// Function that writes the known sector keys of the card to a writer in the Flipper key cache format,
+// the file Flipper keeps in /ext/nfc/.cache to skip the key search when reading the card again
+func WriteFlipperKeyCache(w io.Writer, c *MifareCard) error {
+	typeName, err := classicTypeName(len(c.Blocks))
+	if err != nil {
+		return err
+	}
+
+	sectorKeys := c.SectorKeys()
+	var mapA, mapB uint64
+	for _, sk := range sectorKeys {
+		if sk.KeyA != nil {
+			mapA |= 1 << uint(sk.Sector)
+		}
+		if sk.KeyB != nil {
+			mapB |= 1 << uint(sk.Sector)
+		}
+	}
+
+	if _, err := fmt.Fprintf(w, `Filetype: Flipper NFC keys
+Version: 1
+Mifare Classic type: %s
+Key A map: %s
+Key B map: %s
+`, typeName, keyMap(mapA), keyMap(mapB)); err != nil {
+		return err
+	}
+	for _, sk := range sectorKeys {
+		if sk.KeyA != nil {
+			if _, err := fmt.Fprintf(w, "Key A sector %d: %s\n", sk.Sector, sk.KeyA); err != nil {
+				return err
+			}
+		}
+		if sk.KeyB != nil {
+			if _, err := fmt.Fprintf(w, "Key B sector %d: %s\n", sk.Sector, sk.KeyB); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// Function that formats a key map, one bit per sector, as big-endian hex bytes
+func keyMap(m uint64) HexData {
+	bs := make(HexData, 8)
+	for i := range bs {
+		bs[i] = byte(m >> uint(56-8*i))
+	}
+	return bs
+}
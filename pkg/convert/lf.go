@@ -0,0 +1,162 @@
+package convert
+
+import (
+	"bufio"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+)
+
+// Struct representing a low-frequency (125 kHz) RFID key as the Flipper stores it
+type LFCard struct {
+	KeyType string // Flipper protocol name, e.g. EM4100, H10301, Indala26
+	Data    HexData
+	Raw     HexData // frame as read by the Proxmark3, when the Flipper data does not cover it
+}
+
+// LF cards are stored by Flipper in .rfid files
+func (c *LFCard) FlipperExt() string {
+	return ".rfid"
+}
+
+// Writing an LF card produces a Flipper RFID file
+func (c *LFCard) writeFlipper(w io.Writer, _ WriteOptions) error {
+	return WriteFlipperRFID(w, c)
+}
+
+// Regular expressions matching the lines printed by the Proxmark3 LF reader commands
+var (
+	ansiEscapeRe = regexp.MustCompile(`\x1b\[[0-9;]*m`)
+	em410xRe     = regexp.MustCompile(`EM 410x ID\s+([0-9A-Fa-f]{10})\b`)
+	hid10301Re   = regexp.MustCompile(`\bH10301\b.*FC:\s*(\d+)\s+CN:\s*(\d+)`)
+	hidRawRe     = regexp.MustCompile(`(?i)^\W*raw:\s*([0-9A-Fa-f]+)\s*$`)
+	hidLineRe    = regexp.MustCompile(`\bHID\b`)
+	indalaRawRe  = regexp.MustCompile(`(?i)Indala\b.*\bRaw:\s*([0-9A-Fa-f]+)`)
+	// commands and tag names telling LF client output apart from other text
+	lfOutputRe = regexp.MustCompile(`(?i)\blf (search|em|hid|indala)\b|\bEM ?410x\b|\bHID\b|\bIndala\b|125/134 kHz`)
+)
+
+// Function that parses the output of `lf em 410x reader`, `lf hid reader` or `lf indala reader`
+// and returns the first credential found as an LFCard struct
+func ParseProxmark3LF(r io.Reader) (*LFCard, error) {
+	var hidRaw string
+	seenHID := false
+
+	sc := bufio.NewScanner(r)
+	for sc.Scan() {
+		line := ansiEscapeRe.ReplaceAllString(sc.Text(), "")
+
+		if m := em410xRe.FindStringSubmatch(line); m != nil {
+			id, err := DecodeHexData(m[1])
+			if err != nil {
+				return nil, fmt.Errorf("cannot parse EM410x ID: %w", err)
+			}
+			return &LFCard{KeyType: "EM4100", Data: id}, nil
+		}
+
+		if m := hid10301Re.FindStringSubmatch(line); m != nil {
+			return parseHID10301(m[1], m[2])
+		}
+
+		if m := indalaRawRe.FindStringSubmatch(line); m != nil {
+			return parseIndala26(m[1])
+		}
+
+		if hidLineRe.MatchString(line) {
+			seenHID = true
+		} else if m := hidRawRe.FindStringSubmatch(line); m != nil && seenHID && hidRaw == "" {
+			hidRaw = m[1]
+		}
+	}
+	if err := sc.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read Proxmark3 LF output: %w", err)
+	}
+
+	if hidRaw != "" {
+		return parseHIDProx(hidRaw)
+	}
+
+	return nil, ErrNoLFCredential
+}
+
+// Function that builds an H10301 (HID 26-bit) key from its facility code and card number
+func parseHID10301(fcStr, cnStr string) (*LFCard, error) {
+	fc, err := strconv.ParseUint(fcStr, 10, 8)
+	if err != nil {
+		return nil, fmt.Errorf("cannot parse HID facility code '%s': %w", fcStr, err)
+	}
+	cn, err := strconv.ParseUint(cnStr, 10, 16)
+	if err != nil {
+		return nil, fmt.Errorf("cannot parse HID card number '%s': %w", cnStr, err)
+	}
+
+	return &LFCard{
+		KeyType: "H10301",
+		Data:    HexData{byte(fc), byte(cn >> 8), byte(cn)},
+	}, nil
+}
+
+// Function that builds a generic HID Prox key from the raw value printed by the Proxmark3
+func parseHIDProx(rawStr string) (*LFCard, error) {
+	if len(rawStr)%2 != 0 {
+		rawStr = "0" + rawStr
+	}
+	raw, err := hex.DecodeString(rawStr)
+	if err != nil {
+		return nil, fmt.Errorf("cannot parse HID raw data '%s': %w", rawStr, err)
+	}
+
+	// Flipper keeps the last 6 bytes of the HID Prox frame
+	const hidProxSize = 6
+	data := make(HexData, hidProxSize)
+	if len(raw) > hidProxSize {
+		raw = raw[len(raw)-hidProxSize:]
+	}
+	copy(data[hidProxSize-len(raw):], raw)
+
+	return &LFCard{KeyType: "HIDProx", Data: data}, nil
+}
+
+// Function that builds an Indala26 key from the 64-bit raw frame printed by the Proxmark3
+func parseIndala26(rawStr string) (*LFCard, error) {
+	raw, err := hex.DecodeString(rawStr)
+	if err != nil {
+		return nil, fmt.Errorf("cannot parse Indala raw data '%s': %w", rawStr, err)
+	}
+	if len(raw) != 8 {
+		return nil, fmt.Errorf("unsupported Indala frame length: %d bits, only 64-bit frames are supported", len(raw)*8)
+	}
+
+	// Flipper stores the frame without its 33-bit preamble and the two checksum bits,
+	// the same way its Indala26 decoder does
+	data := make(HexData, 4)
+	copyBits(data, 0, raw, 33, 22)
+	copyBits(data, 22, raw, 55, 5)
+	copyBits(data, 27, raw, 61, 2)
+
+	return &LFCard{KeyType: "Indala26", Data: data, Raw: raw}, nil
+}
+
+// Function that copies n bits, MSB first, from src starting at bit srcPos into dst starting at bit dstPos
+func copyBits(dst []byte, dstPos int, src []byte, srcPos int, n int) {
+	for i := 0; i < n; i++ {
+		s, d := srcPos+i, dstPos+i
+		bit := (src[s/8] >> (7 - uint(s%8))) & 1
+		dst[d/8] &^= 1 << (7 - uint(d%8))
+		dst[d/8] |= bit << (7 - uint(d%8))
+	}
+}
+
+// Function that writes the LF key to a writer in Flipper RFID format
+func WriteFlipperRFID(w io.Writer, c *LFCard) error {
+	_, err := fmt.Fprintf(w, `Filetype: Flipper RFID key
+Version: 1
+Key type: %s
+# Data size for %s is %d
+Data: %s
+`, c.KeyType, c.KeyType, len(c.Data), c.Data)
+
+	return err
+}
@@ -0,0 +1,107 @@
+package convert
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+)
+
+// Output of the Proxmark3 client LF reader commands, as printed by the Iceman client
+const (
+	em410xReaderOutput = "[usb] pm3 --> lf em 410x reader\n" +
+		"\x1b[32m[+]\x1b[0m EM 410x ID \x1b[32m0F0368568B\x1b[0m\n" +
+		"[+] EM410x ( RF/64 )\n" +
+		"[=] -------- Possible de-scramble patterns ---------\n" +
+		"[+] Unique TAG ID      : F0C0166AD1\n" +
+		"[=] HoneyWell IdentKey\n" +
+		"[+]     DEZ 8          : 06837899\n"
+
+	hidReaderOutput = "[usb] pm3 --> lf hid reader\n" +
+		"[+] [H10301  ] HID H10301 26-bit                 FC: 118  CN: 1603  parity ( ok )\n" +
+		"[+] [ind26   ] Indala 26-bit                     FC: 1899  CN: 6  parity ( ok )\n" +
+		"[=] found 2 matching formats\n" +
+		"[+] DemodBuffer:\n" +
+		"[+] 1D5559555569A9A555A59569\n" +
+		"\n" +
+		"[=] raw: 000000000000002006ec0c86\n"
+
+	hidProxReaderOutput = "[usb] pm3 --> lf hid reader\n" +
+		"[+] HID Prox - 2006ec0c86 (1603) - len: 26 bit\n" +
+		"[=] raw: 000000000000002006ec0c86\n"
+
+	indalaReaderOutput = "[usb] pm3 --> lf indala reader\n" +
+		"[+] Indala (len 64)  Raw: a0000000c2c436c1\n" +
+		"[+] Fmt 26 FC: 130 Card: 7390 Parity: 11\n"
+)
+
+func TestParseProxmark3LF(t *testing.T) {
+	tests := []struct {
+		name    string
+		output  string
+		keyType string
+		data    string
+		raw     string
+	}{
+		{"EM410x", em410xReaderOutput, "EM4100", "0F 03 68 56 8B", ""},
+		{"HID H10301", hidReaderOutput, "H10301", "76 06 43", ""},
+		{"HID Prox raw", hidProxReaderOutput, "HIDProx", "00 20 06 EC 0C 86", ""},
+		{"Indala", indalaReaderOutput, "Indala26", "85 88 6D 80", "A0 00 00 00 C2 C4 36 C1"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, err := ParseProxmark3LF(strings.NewReader(tt.output))
+			if err != nil {
+				t.Fatalf("ParseProxmark3LF: %v", err)
+			}
+			if c.KeyType != tt.keyType {
+				t.Errorf("key type = %s, want %s", c.KeyType, tt.keyType)
+			}
+			if got := c.Data.String(); got != tt.data {
+				t.Errorf("data = %s, want %s", got, tt.data)
+			}
+			if got := c.Raw.String(); got != tt.raw {
+				t.Errorf("raw = %s, want %s", got, tt.raw)
+			}
+		})
+	}
+}
+
+func TestParseProxmark3LFErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		output string
+		want   error
+	}{
+		{"no credential", "[usb] pm3 --> lf search\n[-] No known 125/134 kHz tags found!\n", ErrNoLFCredential},
+		{"raw line without HID", "[=] raw: 000000000000002006ec0c86\n", ErrNoLFCredential},
+		{"Indala 224-bit", "[+] Indala (len 224)  Raw: 80000001b23523a6c2e31eba3cbee4afb3c6ad1fcf649393928c14e5\n", nil},
+		{"HID card number out of range", "[+] [H10301  ] HID H10301 26-bit FC: 118  CN: 70000\n", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseProxmark3LF(strings.NewReader(tt.output))
+			if err == nil {
+				t.Fatal("ParseProxmark3LF succeeded, want an error")
+			}
+			if tt.want != nil && !errors.Is(err, tt.want) {
+				t.Errorf("error = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestWriteFlipperRFID(t *testing.T) {
+	c, err := ParseProxmark3LF(strings.NewReader(em410xReaderOutput))
+	if err != nil {
+		t.Fatalf("ParseProxmark3LF: %v", err)
+	}
+	var buf bytes.Buffer
+	if err := WriteFlipperRFID(&buf, c); err != nil {
+		t.Fatalf("WriteFlipperRFID: %v", err)
+	}
+	want := "Filetype: Flipper RFID key\nVersion: 1\nKey type: EM4100\n# Data size for EM4100 is 5\nData: 0F 03 68 56 8B\n"
+	if got := buf.String(); got != want {
+		t.Errorf("WriteFlipperRFID wrote\n%s\nwant\n%s", got, want)
+	}
+}
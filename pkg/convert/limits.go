@@ -0,0 +1,103 @@
+package convert
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// Limits enforced by the parsers, so corrupted dumps fail with an error instead of producing malformed files
+const (
+	// Largest dump read, far more than the largest card dump in any supported format
+	MaxDumpSize = 1 << 20
+	// Number of blocks of the largest Mifare Classic card, the 4K
+	ClassicMaxBlocks = 256
+	// Number of pages a Mifare Ultralight or NTAG card can address with its one byte page numbers
+	UltralightMaxPages = 256
+)
+
+// Error returned when a dump is larger than MaxDumpSize
+var ErrDumpTooLarge = fmt.Errorf("dump is larger than %d bytes", MaxDumpSize)
+
+// PositionError reports a problem at a line of a text or JSON dump, and at a column when known
+type PositionError struct {
+	Line   int
+	Column int
+	Err    error
+}
+
+// Error method for PositionError to satisfy the error interface
+func (e *PositionError) Error() string {
+	if e.Column > 0 {
+		return fmt.Sprintf("line %d, column %d: %v", e.Line, e.Column, e.Err)
+	}
+	return fmt.Sprintf("line %d: %v", e.Line, e.Err)
+}
+
+// Unwrap method for PositionError to expose the underlying error
+func (e *PositionError) Unwrap() error {
+	return e.Err
+}
+
+// Function that reads a whole dump, failing with ErrDumpTooLarge instead of reading past MaxDumpSize.
+// The parsers read their input with it, and so should callers reading a dump before parsing it
+func ReadDump(r io.Reader) ([]byte, error) {
+	data, err := io.ReadAll(io.LimitReader(r, MaxDumpSize+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(data) > MaxDumpSize {
+		return nil, ErrDumpTooLarge
+	}
+	return data, nil
+}
+
+// Function that returns the line and column, both counted from 1, of the last byte before offset in data,
+// which is where the json package stops reading at an error
+func lineColumn(data []byte, offset int64) (int, int) {
+	if offset > int64(len(data)) {
+		offset = int64(len(data))
+	}
+	before := data[:offset]
+	line := bytes.Count(before, []byte{'\n'}) + 1
+	return line, len(before) - bytes.LastIndexByte(before, '\n') - 1
+}
+
+// Function that decodes JSON data into v, reporting syntax errors and mistyped values at their line and column
+func unmarshalJSON(data []byte, v interface{}) error {
+	err := json.Unmarshal(data, v)
+
+	var offset int64
+	var syntaxErr *json.SyntaxError
+	var typeErr *json.UnmarshalTypeError
+	switch {
+	case errors.As(err, &syntaxErr):
+		offset = syntaxErr.Offset
+	case errors.As(err, &typeErr):
+		offset = typeErr.Offset
+	default:
+		return err
+	}
+	line, column := lineColumn(data, offset)
+	return &PositionError{Line: line, Column: column, Err: err}
+}
+
+// Function that reads a JSON dump of at most MaxDumpSize bytes and decodes it into v like unmarshalJSON
+func decodeJSON(r io.Reader, v interface{}) error {
+	data, err := ReadDump(r)
+	if err != nil {
+		return err
+	}
+	return unmarshalJSON(data, v)
+}
+
+// Function that checks the UID of an ISO 14443-A card, which is 4, 7 or 10 bytes long
+func checkUIDSize(uid HexData) error {
+	switch len(uid) {
+	case 4, 7, 10:
+		return nil
+	}
+	return &FieldError{Field: "UID", Err: fmt.Errorf("expecting 4, 7 or 10 bytes, got %d", len(uid))}
+}
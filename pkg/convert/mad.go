@@ -0,0 +1,91 @@
+package convert
+
+import "fmt"
+
+// Application ID the NFC Forum assigned to NDEF data in the Mifare Application Directory
+const NDEFAID = 0xE103
+
+// Struct representing the Mifare Application Directory: the application ID of every sector
+type MAD struct {
+	Version int
+	AIDs    map[int]uint16 // application ID by sector, for the sectors the directory covers
+}
+
+// Function that returns the sectors holding the application, in order
+func (m *MAD) Sectors(aid uint16) []int {
+	var sectors []int
+	for s := 1; s < 40; s++ {
+		if a, ok := m.AIDs[s]; ok && a == aid {
+			sectors = append(sectors, s)
+		}
+	}
+	return sectors
+}
+
+// Function that decodes the Mifare Application Directory of sector 0 and, on 4K cards, of sector 16.
+// A directory with a wrong CRC is returned along with an error describing the mismatch.
+func (c *MifareCard) MAD() (*MAD, error) {
+	if len(c.Blocks) < 4 {
+		return nil, fmt.Errorf("card is too small to hold a MAD")
+	}
+	mad1 := append(append([]byte(nil), c.Blocks[1].Data...), c.Blocks[2].Data...)
+	if !c.Blocks[1].IsComplete() || !c.Blocks[2].IsComplete() || len(mad1) != 2*ClassicBlockSize {
+		return nil, fmt.Errorf("MAD blocks 1 and 2 are unknown")
+	}
+
+	// the DA bit of the general purpose byte of sector 0 tells a MAD is present
+	trailer := c.Blocks[3]
+	if trailer.IsUnknown(9) {
+		return nil, fmt.Errorf("general purpose byte of sector 0 is unknown")
+	}
+	gpb := trailer.Data[9]
+	if gpb&0x80 == 0 {
+		return nil, fmt.Errorf("sector 0 does not announce a MAD")
+	}
+
+	m := &MAD{Version: 1, AIDs: make(map[int]uint16)}
+	for s := 1; s <= 15; s++ {
+		m.AIDs[s] = uint16(mad1[2*s]) | uint16(mad1[2*s+1])<<8
+	}
+	var crcErr error
+	if crc := madCRC(mad1[1:]); crc != mad1[0] {
+		crcErr = fmt.Errorf("MAD1 CRC %02X does not match, expecting %02X", mad1[0], crc)
+	}
+
+	// MAD version 2 continues in sector 16
+	if gpb&0x03 == 0x02 && len(c.Blocks) > 67 {
+		var mad2 []byte
+		for b := 64; b <= 66; b++ {
+			if !c.Blocks[b].IsComplete() {
+				return m, fmt.Errorf("MAD2 block %d is unknown", b)
+			}
+			mad2 = append(mad2, c.Blocks[b].Data...)
+		}
+		m.Version = 2
+		for s := 17; s < 40; s++ {
+			i := 2 * (s - 16)
+			m.AIDs[s] = uint16(mad2[i]) | uint16(mad2[i+1])<<8
+		}
+		if crc := madCRC(mad2[1:]); crc != mad2[0] && crcErr == nil {
+			crcErr = fmt.Errorf("MAD2 CRC %02X does not match, expecting %02X", mad2[0], crc)
+		}
+	}
+
+	return m, crcErr
+}
+
+// Function that computes the CRC-8 of a MAD: polynomial 0x1D, preset 0xC7
+func madCRC(data []byte) byte {
+	crc := byte(0xC7)
+	for _, b := range data {
+		crc ^= b
+		for i := 0; i < 8; i++ {
+			if crc&0x80 != 0 {
+				crc = crc<<1 ^ 0x1D
+			} else {
+				crc <<= 1
+			}
+		}
+	}
+	return crc
+}
@@ -0,0 +1,115 @@
+package convert
+
+import (
+	"bytes"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Function that returns the ATQA (low byte first) and SAK of a genuine Mifare Classic card of the size
+// and UID length of the dump, nil when there is no such card
+func classicIdentity(blocksNum, uidLen int) (atqa, sak HexData) {
+	var size byte
+	switch blocksNum {
+	case 20:
+		atqa, sak = HexData{0x04, 0x00}, HexData{0x09}
+		size = 0x04
+	case 64:
+		atqa, sak = HexData{0x04, 0x00}, HexData{0x08}
+		size = 0x04
+	case 256:
+		atqa, sak = HexData{0x02, 0x00}, HexData{0x18}
+		size = 0x02
+	default:
+		return nil, nil
+	}
+	if uidLen == 7 {
+		atqa[0] = size | 0x40
+	}
+	return atqa, sak
+}
+
+// Function that reports whether a sector trailer permission ("A|B", "B", "never"...) is granted by a known key
+func permittedByKnownKey(perm string, keys SectorKeys) bool {
+	return strings.Contains(perm, "A") && keys.KeyA != nil || strings.Contains(perm, "B") && keys.KeyB != nil
+}
+
+// Function that returns the sectors whose data blocks cannot all be written back with the keys of the dump,
+// block 0 excepted: the access bits are unknown, forbid writing, or only allow an unknown key
+func (c *MifareCard) unwritableSectors() []int {
+	var sectors []int
+	for _, keys := range c.SectorKeys() {
+		s := keys.Sector
+		ab, err := DecodeAccessBits(c.Blocks[ClassicSectorTrailer(s)])
+		if err != nil {
+			sectors = append(sectors, s)
+			continue
+		}
+		first := ClassicSectorFirstBlock(s)
+		groupSize := (ClassicSectorBlocks(s) - 1) / 3
+		for b := first; b < ClassicSectorTrailer(s); b++ {
+			if b == 0 {
+				continue
+			}
+			if !permittedByKnownKey(dataAccess[ab[(b-first)/groupSize]&7][1], keys) {
+				sectors = append(sectors, s)
+				break
+			}
+		}
+	}
+	return sectors
+}
+
+// Function that returns hints for picking the blank card a Mifare Classic dump is cloned onto: which magic
+// card generations can take its block 0 and answer its ATQA/SAK, and whether Flipper's "Write to Initial Card"
+// can restore the dump onto the original card
+func (c *MifareCard) CloneHints() []string {
+	var hints []string
+	add := func(format string, args ...interface{}) {
+		hints = append(hints, fmt.Sprintf(format, args...))
+	}
+
+	// block 0
+	switch len(c.UID) {
+	case 4:
+		add("block 0 is read-only on genuine cards, cloning the UID needs a gen1a, gen2 (CUID) or gen4 (GTU) magic card")
+	case 7:
+		add("7-byte UID: block 0 can only be cloned onto a 7-byte gen2 (CUID) or gen4 (GTU) magic card, gen1a blanks have 4-byte UIDs")
+	}
+	switch len(c.Blocks) {
+	case 20, 128:
+		add("%s: no gen1a or gen2 blank has this size, only a gen4 (GTU) card configured for it", c.TypeName())
+	case 256:
+		add("%s: the magic card must be a 4K one", c.TypeName())
+	}
+
+	// ATQA and SAK
+	atqa, sak := classicIdentity(len(c.Blocks), len(c.UID))
+	switch {
+	case len(c.SAK) == 1 && c.SAK[0]&0x20 != 0:
+		add("SAK %s announces ISO 14443-4: only a gen4 (GTU) card can answer it, readers using that layer will still reject a clone", c.SAK)
+	case atqa != nil && (!bytes.Equal(c.ATQA, atqa) || !bytes.Equal(c.SAK, sak)):
+		add("ATQA %s / SAK %s differ from the %s defaults %s / %s: gen1a cards answer fixed values, use a gen2 (CUID) card answering from block 0 or a gen4 (GTU) card",
+			c.ATQA, c.SAK, c.TypeName(), atqa, sak)
+	}
+	if len(c.Blocks) > 0 {
+		if b0, err := DecodeBlock0(c.Blocks[0]); err == nil && (!bytes.Equal(b0.ATQA, c.ATQA) || !bytes.Equal(b0.SAK, c.SAK)) {
+			add("block 0 holds ATQA %s / SAK %s but the card answers %s / %s: a gen2 card answering from block 0 will not match the original",
+				b0.ATQA, b0.SAK, c.ATQA, c.SAK)
+		}
+	}
+
+	// Flipper's "Write to Initial Card" writes the data blocks back onto the original card with its keys
+	if sectors := c.unwritableSectors(); len(sectors) > 0 {
+		names := make([]string, len(sectors))
+		for i, s := range sectors {
+			names[i] = strconv.Itoa(s)
+		}
+		add("Flipper's Write to Initial Card cannot restore sectors %s: no known key may write their data blocks", strings.Join(names, ", "))
+	} else {
+		add("Flipper's Write to Initial Card can restore every data block onto the original card with the keys of the dump")
+	}
+
+	return hints
+}
@@ -0,0 +1,87 @@
+package convert
+
+import (
+	"bytes"
+	"fmt"
+)
+
+// MergeConflictError reports a block known with different data in two of the merged dumps
+type MergeConflictError struct {
+	Block int
+	A, B  Block
+}
+
+// Error method for MergeConflictError to satisfy the error interface
+func (e *MergeConflictError) Error() string {
+	return fmt.Sprintf("conflicting data for block %d: %s vs %s", e.Block, e.A, e.B)
+}
+
+// Function that merges partial dumps of the same card: every byte known in any of the dumps is
+// taken over, and a byte known with different values in two dumps is a conflict. The dumps must agree
+// on UID, ATQA and SAK, and the warnings of every dump are kept in the merged card
+func MergeMifare(cards ...*MifareCard) (*MifareCard, error) {
+	if len(cards) == 0 {
+		return nil, fmt.Errorf("no dumps to merge")
+	}
+
+	first := cards[0]
+	merged := &MifareCard{
+		UID:  first.UID,
+		ATQA: first.ATQA,
+		SAK:  first.SAK,
+	}
+	for _, c := range cards {
+		if !bytes.Equal(c.UID, first.UID) {
+			return nil, fmt.Errorf("cannot merge dumps of different cards: UID %s vs %s", first.UID, c.UID)
+		}
+		if !bytes.Equal(c.ATQA, first.ATQA) {
+			return nil, fmt.Errorf("cannot merge dumps of different cards: ATQA %s vs %s", first.ATQA, c.ATQA)
+		}
+		if !bytes.Equal(c.SAK, first.SAK) {
+			return nil, fmt.Errorf("cannot merge dumps of different cards: SAK %s vs %s", first.SAK, c.SAK)
+		}
+		for _, w := range c.Warnings {
+			if !containsString(merged.Warnings, w) {
+				merged.Warnings = append(merged.Warnings, w)
+			}
+		}
+		for len(merged.Blocks) < len(c.Blocks) {
+			merged.Blocks = append(merged.Blocks, UnknownBlock(ClassicBlockSize))
+		}
+	}
+
+	for _, c := range cards {
+		for i, b := range c.Blocks {
+			m := &merged.Blocks[i]
+			for j := 0; j < len(b.Data) && j < len(m.Data); j++ {
+				if b.IsUnknown(j) {
+					continue
+				}
+				if !m.IsUnknown(j) {
+					if m.Data[j] != b.Data[j] {
+						return nil, &MergeConflictError{Block: i, A: *m, B: b}
+					}
+					continue
+				}
+				m.Data[j], m.Unknown[j] = b.Data[j], false
+			}
+		}
+	}
+
+	for i := range merged.Blocks {
+		if merged.Blocks[i].IsComplete() {
+			merged.Blocks[i].Unknown = nil
+		}
+	}
+	return merged, nil
+}
+
+// Function that reports whether a list of strings contains s
+func containsString(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,48 @@
+package convert
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestMergeMifareIdentification(t *testing.T) {
+	card := func(atqa, sak HexData, warnings ...string) *MifareCard {
+		return &MifareCard{
+			UID:      HexData{0x11, 0x22, 0x33, 0x44},
+			ATQA:     atqa,
+			SAK:      sak,
+			Blocks:   []Block{UnknownBlock(ClassicBlockSize)},
+			Warnings: warnings,
+		}
+	}
+
+	tests := []struct {
+		name  string
+		cards []*MifareCard
+		err   string
+	}{
+		{"ATQA mismatch", []*MifareCard{card(HexData{0x04, 0x00}, HexData{0x08}), card(HexData{0x02, 0x00}, HexData{0x08})}, "ATQA"},
+		{"SAK mismatch", []*MifareCard{card(HexData{0x04, 0x00}, HexData{0x08}), card(HexData{0x04, 0x00}, HexData{0x18})}, "SAK"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := MergeMifare(tt.cards...)
+			if err == nil || !strings.Contains(err.Error(), tt.err) {
+				t.Errorf("MergeMifare error = %v, want a %s mismatch", err, tt.err)
+			}
+		})
+	}
+
+	merged, err := MergeMifare(
+		card(HexData{0x04, 0x00}, HexData{0x08}, "card SAK missing from the dump"),
+		card(HexData{0x04, 0x00}, HexData{0x08}, "card SAK missing from the dump", "block 3 is incomplete"),
+	)
+	if err != nil {
+		t.Fatalf("MergeMifare: %v", err)
+	}
+	want := []string{"card SAK missing from the dump", "block 3 is incomplete"}
+	if !reflect.DeepEqual(merged.Warnings, want) {
+		t.Errorf("merged warnings = %q, want %q", merged.Warnings, want)
+	}
+}
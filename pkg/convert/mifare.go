@@ -0,0 +1,395 @@
+package convert
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// Struct representing the data structure of a Mifare card
+type MifareCard struct {
+	UID      HexData
+	ATQA     HexData // low byte first, as sent by the card
+	SAK      HexData
+	Blocks   []Block
+	Warnings []string // problems noticed while parsing which did not stop the conversion
+}
+
+// Function that returns the indexes of the blocks which have unknown bytes
+func (c *MifareCard) IncompleteBlocks() []int {
+	var incomplete []int
+	for i, b := range c.Blocks {
+		if !b.IsComplete() {
+			incomplete = append(incomplete, i)
+		}
+	}
+	return incomplete
+}
+
+// Function that returns the name of the card type, e.g. "Mifare Classic 1K"
+func (c *MifareCard) TypeName() string {
+	size, err := classicTypeName(len(c.Blocks))
+	if err != nil {
+		return "Mifare Classic"
+	}
+	return "Mifare Classic " + size
+}
+
+// Mifare cards are stored by Flipper in .nfc files
+func (c *MifareCard) FlipperExt() string {
+	return ".nfc"
+}
+
+// Writing a Mifare card produces a Flipper NFC file
+func (c *MifareCard) writeFlipper(w io.Writer, opts WriteOptions) error {
+	return WriteFlipperNFCWithOptions(w, c, opts)
+}
+
+// Function that parses the Proxmark3 JSON data and returns a MifareCard struct
+func ParseProxmark3JSON(r io.Reader) (*MifareCard, error) {
+	return ParseProxmark3JSONWithOptions(r, ParseOptions{})
+}
+
+// Function that parses the Proxmark3 JSON data, tuned by the options, and returns a MifareCard struct
+func ParseProxmark3JSONWithOptions(r io.Reader, opts ParseOptions) (*MifareCard, error) {
+	var proxmark3JSON struct {
+		Created  string `json:"Created"`
+		FileType string `json:"FileType"`
+		Card     struct {
+			UID  string `json:"UID"`
+			ATQA string `json:"ATQA"`
+			SAK  string `json:"SAK"`
+		} `json:"Card"`
+		Blocks     map[string]string         `json:"blocks"`
+		SectorKeys map[string]sectorKeysJSON `json:"SectorKeys"`
+	}
+
+	if err := decodeJSON(r, &proxmark3JSON); err != nil {
+		return nil, fmt.Errorf("failed to decode Proxmark3 JSON file: %w", err)
+	}
+
+	if proxmark3JSON.Created != "proxmark3" {
+		return nil, ErrNotProxmark3
+	}
+
+	if proxmark3JSON.FileType != "mfcard" {
+		return nil, ErrUnsupportedFileType
+	}
+
+	card := &proxmark3JSON.Card
+	c, err := newMifareCard(card.UID, card.ATQA, card.SAK, proxmark3JSON.Blocks, opts)
+	if err != nil {
+		return nil, err
+	}
+	if err := c.applySectorKeys(proxmark3JSON.SectorKeys); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// Function that writes the access conditions of a sector as Flipper file comments
+func writeAccessComments(w io.Writer, sa SectorAccess) error {
+	if sa.Error != "" {
+		_, err := fmt.Fprintf(w, "# Sector %d access conditions: %s\n", sa.Sector, sa.Error)
+		return err
+	}
+	if _, err := fmt.Fprintf(w, "# Sector %d access conditions\n", sa.Sector); err != nil {
+		return err
+	}
+	for _, line := range sa.Conditions {
+		if _, err := fmt.Fprintf(w, "#   %s\n", line); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Function that writes a Mifare card as a Proxmark3 JSON dump, blocks in order and unknown bytes written as '??'
+func WriteProxmark3JSON(w io.Writer, c *MifareCard) error {
+	field := func(v string) string {
+		b, _ := json.Marshal(v)
+		return string(b)
+	}
+	compact := func(s string) string {
+		return strings.ReplaceAll(s, " ", "")
+	}
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, `{
+  "Created": "proxmark3",
+  "FileType": "mfcard",
+  "Card": {
+    "UID": %s,
+    "ATQA": %s,
+    "SAK": %s
+  },
+  "blocks": {
+`, field(compact(c.UID.String())), field(compact(c.ATQA.String())), field(compact(c.SAK.String())))
+	for i, b := range c.Blocks {
+		sep := ","
+		if i == len(c.Blocks)-1 {
+			sep = ""
+		}
+		fmt.Fprintf(&sb, "    \"%d\": %s%s\n", i, field(compact(b.String())), sep)
+	}
+	sb.WriteString("  }\n}\n")
+
+	_, err := io.WriteString(w, sb.String())
+	return err
+}
+
+// Function that builds a MifareCard from the hex strings of a dump, normalizing ATQA and SAK and
+// filling in or cross-checking the identification with block 0
+func newMifareCard(uidStr, atqaStr, sakStr string, blocksMap map[string]string, opts ParseOptions) (*MifareCard, error) {
+	uid, err := DecodeHexData(uidStr)
+	if err != nil {
+		return nil, &FieldError{Field: "UID", Err: err}
+	}
+	atqa, err := DecodeHexData(atqaStr)
+	if err != nil {
+		return nil, &FieldError{Field: "ATQA", Err: err}
+	}
+	sak, err := DecodeHexData(sakStr)
+	if err != nil {
+		return nil, &FieldError{Field: "SAK", Err: err}
+	}
+
+	var warnings []string
+	if len(atqa) > 0 {
+		var warning string
+		dumpATQA := atqa
+		atqa, warning, err = NormalizeATQA(atqa, opts.SwapATQA)
+		if err != nil {
+			return nil, err
+		}
+		if warning != "" {
+			warnings = append(warnings, warning)
+		}
+		opts.debug("ATQA byte order", "dump", dumpATQA, "card", atqa, "swapped", !bytes.Equal(dumpATQA, atqa), "forced", opts.SwapATQA)
+	}
+	if len(sak) > 0 {
+		if sak, err = normalizeSAK(sak); err != nil {
+			return nil, err
+		}
+	}
+
+	// block 0 repeats the identification, so use it to fill in or cross-check the Card section
+	if block0Str, ok := blocksMap["0"]; ok {
+		block0, err := DecodeBlock(block0Str)
+		if err != nil {
+			return nil, &BlockError{Block: 0, Err: err}
+		}
+		if b0, err := DecodeBlock0(block0); err == nil {
+			warnings = append(warnings, reconcileBlock0(&uid, &atqa, &sak, b0)...)
+			opts.debug("identification checked against block 0", "uid", uid, "atqa", atqa, "sak", sak)
+		} else if block0.IsComplete() {
+			warnings = append(warnings, err.Error())
+		} else {
+			opts.debug("block 0 is incomplete, identification taken from the Card section")
+		}
+	}
+	if len(uid) == 0 || len(atqa) == 0 || len(sak) == 0 {
+		return nil, &FieldError{Field: "identification", Err: errors.New("Card section is incomplete and block 0 cannot be used instead")}
+	}
+	if err := checkUIDSize(uid); err != nil {
+		return nil, err
+	}
+
+	blocks, err := decodeBlocksMap(blocksMap, classicBlocksBySAK(sak), opts)
+	if err != nil {
+		return nil, err
+	}
+
+	return &MifareCard{
+		UID:      uid,
+		ATQA:     atqa,
+		SAK:      sak,
+		Blocks:   blocks,
+		Warnings: warnings,
+	}, nil
+}
+
+// Numbers of blocks of the Mifare Classic card sizes, from the smallest to the largest
+var classicBlockCounts = []int{20, 64, 128, 256}
+
+// Function that decodes the blocks of a dump, filling the blocks missing from it with unknown data
+// up to the card size given by sakBlocks, or up to the next card size when the SAK is not conclusive.
+// Blocks past the end of a 4K card and blocks which are not 16 bytes long are rejected
+func decodeBlocksMap(blocksMap map[string]string, sakBlocks int, opts ParseOptions) ([]Block, error) {
+	decoded := make(map[int]Block, len(blocksMap))
+	maxBlock := -1
+	for blockNumStr, blockData := range blocksMap {
+		i, err := strconv.Atoi(blockNumStr)
+		if err != nil || i < 0 {
+			return nil, fmt.Errorf("invalid block number '%s'", blockNumStr)
+		}
+		if i >= ClassicMaxBlocks {
+			return nil, &BlockError{Block: i, Err: fmt.Errorf("past the last block %d of a Mifare Classic 4K", ClassicMaxBlocks-1)}
+		}
+		b, err := DecodeBlock(blockData)
+		if err != nil {
+			return nil, &BlockError{Block: i, Err: err}
+		}
+		if len(b.Data) != ClassicBlockSize {
+			return nil, &BlockError{Block: i, Err: fmt.Errorf("expecting %d bytes, got %d", ClassicBlockSize, len(b.Data))}
+		}
+		decoded[i] = b
+		if i > maxBlock {
+			maxBlock = i
+		}
+	}
+
+	// a partial dump still covers the whole card, so round up to the next card size
+	blocksNum := maxBlock + 1
+	if blocksNum <= sakBlocks {
+		blocksNum = sakBlocks
+	} else {
+		for _, n := range classicBlockCounts {
+			if blocksNum <= n {
+				blocksNum = n
+				break
+			}
+		}
+	}
+
+	opts.debug("card size", "blocks", blocksNum, "last_block", maxBlock, "sak_blocks", sakBlocks)
+
+	var missing []int
+	blocks := make([]Block, blocksNum)
+	for i := range blocks {
+		if b, ok := decoded[i]; ok {
+			blocks[i] = b
+		} else {
+			blocks[i] = UnknownBlock(ClassicBlockSize)
+			missing = append(missing, i)
+		}
+	}
+	if len(missing) > 0 {
+		opts.debug("blocks missing from the dump are unknown", "count", len(missing), "blocks", missing)
+	}
+
+	return blocks, nil
+}
+
+// Flipper NFC file format versions the writer can produce
+const (
+	NFCFormatV2     = 2
+	NFCFormatV3     = 3
+	NFCFormatV4     = 4
+	NFCFormatLatest = NFCFormatV4
+)
+
+// Function that writes Mifare card data to a writer in the latest Flipper NFC format
+func WriteFlipperNFC(w io.Writer, c *MifareCard) error {
+	return WriteFlipperNFCWithOptions(w, c, WriteOptions{})
+}
+
+// Function that writes Mifare card data to a writer in the Flipper NFC format version selected by the options
+func WriteFlipperNFCWithOptions(w io.Writer, c *MifareCard, opts WriteOptions) error {
+	version := opts.NFCVersion
+	if version == 0 {
+		version = NFCFormatLatest
+	}
+
+	typeName, err := classicTypeName(len(c.Blocks))
+	if err != nil {
+		return err
+	}
+
+	var header string
+	switch version {
+	case NFCFormatV2:
+		header = `Filetype: Flipper NFC device
+Version: 2
+# Nfc device type can be UID, Mifare Ultralight, Mifare Classic, Bank card
+Device type: Mifare Classic
+# UID, ATQA and SAK are common for all formats
+UID: %[1]s
+ATQA: %[2]s
+SAK: %[3]s
+`
+	case NFCFormatV3:
+		header = `Filetype: Flipper NFC device
+Version: 3
+# Nfc device type can be UID, Mifare Ultralight, Mifare Classic
+Device type: Mifare Classic
+# UID, ATQA and SAK are common for all formats
+UID: %[1]s
+ATQA: %[2]s
+SAK: %[3]s
+`
+	case NFCFormatV4:
+		header = `Filetype: Flipper NFC device
+Version: 4
+# Device type can be ISO14443-3A, ISO14443-3B, ISO14443-4A, NTAG/Ultralight, Mifare Classic, Mifare DESFire, SLIX, ST25TB
+Device type: Mifare Classic
+# UID is common for all formats
+UID: %[1]s
+# ISO14443-3A specific data
+ATQA: %[2]s
+SAK: %[3]s
+`
+	default:
+		return fmt.Errorf("unsupported Flipper NFC format version %d", version)
+	}
+
+	if _, err := fmt.Fprintf(w, header, c.UID, flipperATQA(c.ATQA, version), c.SAK); err != nil {
+		return err
+	}
+	if _, err := fmt.Fprintf(w, `# Mifare Classic specific data
+Mifare Classic type: %s
+Data format version: 2
+# Mifare Classic blocks, '??' means unknown data
+`, typeName); err != nil {
+		return err
+	}
+	for i, block := range c.Blocks {
+		if sector := ClassicBlockSector(i); opts.AnnotateAccess && i == ClassicSectorTrailer(sector) {
+			if err := writeAccessComments(w, c.SectorAccess(sector)); err != nil {
+				return err
+			}
+		}
+		if _, err := fmt.Fprintf(w, "Block %d: %s\n", i, block); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Function that returns the Flipper name of a Mifare Classic card type for the given number of blocks
+func classicTypeName(blocksNum int) (string, error) {
+	switch blocksNum {
+	case 20:
+		return "MINI", nil
+	case 64:
+		return "1K", nil
+	case 128:
+		return "2K", nil
+	case 256:
+		return "4K", nil
+	}
+	return "", fmt.Errorf("%w: %d blocks, expecting 20 (Mini), 64 (1K), 128 (2K) or 256 (4K)", ErrUnsupportedSize, blocksNum)
+}
+
+// Function that returns the number of blocks of the Mifare Classic card identified by its SAK, or 0 when unknown
+func classicBlocksBySAK(sak HexData) int {
+	if len(sak) != 1 {
+		return 0
+	}
+	switch sak[0] {
+	case 0x09:
+		return 20
+	case 0x08, 0x28, 0x88:
+		return 64
+	case 0x19:
+		return 128
+	case 0x18, 0x38, 0x98:
+		return 256
+	}
+	return 0
+}
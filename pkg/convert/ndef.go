@@ -0,0 +1,324 @@
+package convert
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"strings"
+	"unicode/utf16"
+)
+
+// TLV types found in the NDEF data area of a tag
+const (
+	tlvNull       = 0x00
+	tlvNDEF       = 0x03
+	tlvTerminator = 0xFE
+)
+
+// Struct representing a decoded NDEF record
+type NDEFRecord struct {
+	TNF     byte              `json:"tnf"`
+	Type    string            `json:"type"`
+	ID      string            `json:"id,omitempty"`
+	Kind    string            `json:"kind"`   // uri, text, smart-poster, vcard, wifi, mime, external or unknown
+	Fields  map[string]string `json:"fields"` // decoded content, e.g. uri, text, ssid
+	Payload HexData           `json:"payload"`
+}
+
+// String method for NDEFRecord type to print the decoded record on one line
+func (r NDEFRecord) String() string {
+	var sb strings.Builder
+	sb.WriteString(r.Kind)
+	for _, k := range ndefFieldOrder {
+		if v, ok := r.Fields[k]; ok {
+			sb.WriteString(fmt.Sprintf(" %s=%q", k, v))
+		}
+	}
+	if len(r.Fields) == 0 {
+		sb.WriteString(fmt.Sprintf(" type=%q payload=%s", r.Type, r.Payload))
+	}
+	return sb.String()
+}
+
+// Order the decoded fields are printed in
+var ndefFieldOrder = []string{"uri", "title", "lang", "text", "mime", "vcard", "ssid", "auth", "encryption", "key"}
+
+// Struct representing an NDEF message, the content of an NDEF TLV
+type NDEFMessage struct {
+	Records []NDEFRecord `json:"records"`
+}
+
+// Function that extracts the NDEF messages of the sectors the MAD assigns to NDEF. A MAD with a
+// wrong CRC is reported by the error returned along with the messages.
+func (c *MifareCard) NDEF() ([]NDEFMessage, error) {
+	mad, err := c.MAD()
+	if mad == nil {
+		return nil, err
+	}
+	sectors := mad.Sectors(NDEFAID)
+	if len(sectors) == 0 {
+		return nil, errors.New("MAD assigns no sector to NDEF")
+	}
+
+	var data []byte
+	for _, s := range sectors {
+		first := ClassicSectorFirstBlock(s)
+		for b := first; b < ClassicSectorTrailer(s) && b < len(c.Blocks); b++ {
+			if !c.Blocks[b].IsComplete() {
+				return nil, fmt.Errorf("NDEF block %d is unknown", b)
+			}
+			data = append(data, c.Blocks[b].Data...)
+		}
+	}
+
+	msgs, tlvErr := ParseNDEFTLVs(data)
+	if tlvErr != nil {
+		return msgs, tlvErr
+	}
+	// a bad MAD CRC does not stop the extraction but is still worth reporting
+	return msgs, err
+}
+
+// Function that extracts the NDEF messages of the data area following the capability container in page 3
+func (c *UltralightCard) NDEF() ([]NDEFMessage, error) {
+	if len(c.Pages) < 5 || !c.Pages[3].IsComplete() {
+		return nil, errors.New("capability container in page 3 is unknown")
+	}
+	cc := c.Pages[3].Data
+	if cc[0] != 0xE1 {
+		return nil, fmt.Errorf("page 3 holds no NDEF capability container: %s", c.Pages[3])
+	}
+
+	size := int(cc[2]) * 8
+	var data []byte
+	for p := 4; p < len(c.Pages) && len(data) < size; p++ {
+		if !c.Pages[p].IsComplete() {
+			break
+		}
+		data = append(data, c.Pages[p].Data...)
+	}
+	if len(data) > size {
+		data = data[:size]
+	}
+	return ParseNDEFTLVs(data)
+}
+
+// Function that walks the TLV blocks of an NDEF data area and decodes every NDEF message TLV
+func ParseNDEFTLVs(data []byte) ([]NDEFMessage, error) {
+	var msgs []NDEFMessage
+	for i := 0; i < len(data); {
+		t := data[i]
+		i++
+		if t == tlvNull {
+			continue
+		}
+		if t == tlvTerminator {
+			break
+		}
+
+		if i >= len(data) {
+			return msgs, errors.New("truncated TLV length")
+		}
+		l := int(data[i])
+		i++
+		if l == 0xFF {
+			if i+2 > len(data) {
+				return msgs, errors.New("truncated TLV length")
+			}
+			l = int(binary.BigEndian.Uint16(data[i:]))
+			i += 2
+		}
+		if i+l > len(data) {
+			return msgs, fmt.Errorf("TLV %02X of %d bytes runs past the end of the data", t, l)
+		}
+
+		if t == tlvNDEF {
+			records, err := ParseNDEFMessage(data[i : i+l])
+			if err != nil {
+				return msgs, err
+			}
+			msgs = append(msgs, NDEFMessage{Records: records})
+		}
+		i += l
+	}
+	return msgs, nil
+}
+
+// Function that decodes the records of an NDEF message
+func ParseNDEFMessage(data []byte) ([]NDEFRecord, error) {
+	var records []NDEFRecord
+	for i := 0; i < len(data); {
+		header := data[i]
+		i++
+		sr, il := header&0x10 != 0, header&0x08 != 0
+
+		need := 1
+		if sr {
+			need++
+		} else {
+			need += 4
+		}
+		if il {
+			need++
+		}
+		if i+need > len(data) {
+			return records, errors.New("truncated NDEF record header")
+		}
+
+		typeLen := int(data[i])
+		i++
+		var payloadLen int
+		if sr {
+			payloadLen = int(data[i])
+			i++
+		} else {
+			payloadLen = int(binary.BigEndian.Uint32(data[i:]))
+			i += 4
+		}
+		idLen := 0
+		if il {
+			idLen = int(data[i])
+			i++
+		}
+		if payloadLen < 0 || i+typeLen+idLen+payloadLen > len(data) {
+			return records, errors.New("NDEF record runs past the end of the message")
+		}
+
+		r := NDEFRecord{TNF: header & 0x07, Fields: make(map[string]string)}
+		r.Type = string(data[i : i+typeLen])
+		i += typeLen
+		r.ID = string(data[i : i+idLen])
+		i += idLen
+		r.Payload = data[i : i+payloadLen]
+		i += payloadLen
+
+		decodeNDEFRecord(&r)
+		records = append(records, r)
+
+		if header&0x40 != 0 { // ME, message end
+			break
+		}
+	}
+	return records, nil
+}
+
+// URI prefixes abbreviated by the first byte of a URI record
+var uriPrefixes = []string{
+	"", "http://www.", "https://www.", "http://", "https://", "tel:", "mailto:",
+	"ftp://anonymous:anonymous@", "ftp://ftp.", "ftps://", "sftp://", "smb://", "nfs://", "ftp://",
+	"dav://", "news:", "telnet://", "imap:", "rtsp://", "urn:", "pop:", "sip:", "sips:", "tftp:",
+	"btspp://", "btl2cap://", "btgoep://", "tcpobex://", "irdaobex://", "file://", "urn:epc:id:",
+	"urn:epc:tag:", "urn:epc:pat:", "urn:epc:raw:", "urn:epc:", "urn:nfc:",
+}
+
+// Function that decodes the payload of the record types commonly found on tags
+func decodeNDEFRecord(r *NDEFRecord) {
+	p := r.Payload
+	switch {
+	case r.TNF == 0x01 && r.Type == "U" && len(p) > 0:
+		r.Kind = "uri"
+		prefix := ""
+		if int(p[0]) < len(uriPrefixes) {
+			prefix = uriPrefixes[p[0]]
+		}
+		r.Fields["uri"] = prefix + string(p[1:])
+	case r.TNF == 0x01 && r.Type == "T" && len(p) > 0:
+		r.Kind = "text"
+		langLen := int(p[0] & 0x3F)
+		if 1+langLen > len(p) {
+			r.Kind = "unknown"
+			return
+		}
+		r.Fields["lang"] = string(p[1 : 1+langLen])
+		r.Fields["text"] = decodeNDEFText(p[1+langLen:], p[0]&0x80 != 0)
+	case r.TNF == 0x01 && r.Type == "Sp":
+		r.Kind = "smart-poster"
+		nested, _ := ParseNDEFMessage(p)
+		for _, n := range nested {
+			switch n.Kind {
+			case "uri":
+				r.Fields["uri"] = n.Fields["uri"]
+			case "text":
+				r.Fields["title"] = n.Fields["text"]
+			}
+		}
+	case r.TNF == 0x02 && (strings.EqualFold(r.Type, "text/vcard") || strings.EqualFold(r.Type, "text/x-vcard")):
+		r.Kind = "vcard"
+		r.Fields["vcard"] = strings.TrimSpace(string(p))
+	case r.TNF == 0x02 && r.Type == "application/vnd.wfa.wsc":
+		r.Kind = "wifi"
+		decodeWSC(p, r.Fields)
+	case r.TNF == 0x02:
+		r.Kind = "mime"
+		r.Fields["mime"] = r.Type
+	case r.TNF == 0x04:
+		r.Kind = "external"
+	default:
+		r.Kind = "unknown"
+	}
+}
+
+// Function that decodes the text of a text record in UTF-8 or UTF-16
+func decodeNDEFText(b []byte, isUTF16 bool) string {
+	if !isUTF16 {
+		return string(b)
+	}
+	order := binary.ByteOrder(binary.BigEndian)
+	if len(b) >= 2 && b[0] == 0xFF && b[1] == 0xFE {
+		order, b = binary.LittleEndian, b[2:]
+	} else if len(b) >= 2 && b[0] == 0xFE && b[1] == 0xFF {
+		b = b[2:]
+	}
+	u := make([]uint16, len(b)/2)
+	for i := range u {
+		u[i] = order.Uint16(b[2*i:])
+	}
+	return string(utf16.Decode(u))
+}
+
+// Wi-Fi Simple Configuration attributes of a WiFi credential record
+const (
+	wscCredential = 0x100E
+	wscSSID       = 0x1045
+	wscNetworkKey = 0x1027
+	wscAuthType   = 0x1003
+	wscEncType    = 0x100F
+)
+
+// Function that decodes the credential of a Wi-Fi Simple Configuration record
+func decodeWSC(p []byte, fields map[string]string) {
+	authTypes := map[uint16]string{0x01: "open", 0x02: "WPA-Personal", 0x04: "shared", 0x08: "WPA-Enterprise", 0x10: "WPA2-Enterprise", 0x20: "WPA2-Personal", 0x22: "WPA/WPA2-Personal"}
+	encTypes := map[uint16]string{0x01: "none", 0x02: "WEP", 0x04: "TKIP", 0x08: "AES", 0x0C: "AES/TKIP"}
+
+	for i := 0; i+4 <= len(p); {
+		t, l := binary.BigEndian.Uint16(p[i:]), int(binary.BigEndian.Uint16(p[i+2:]))
+		i += 4
+		if i+l > len(p) {
+			return
+		}
+		v := p[i : i+l]
+		switch t {
+		case wscCredential:
+			decodeWSC(v, fields)
+		case wscSSID:
+			fields["ssid"] = string(v)
+		case wscNetworkKey:
+			fields["key"] = string(v)
+		case wscAuthType, wscEncType:
+			if len(v) == 2 {
+				names := authTypes
+				name := "auth"
+				if t == wscEncType {
+					names, name = encTypes, "encryption"
+				}
+				code := binary.BigEndian.Uint16(v)
+				if s, ok := names[code]; ok {
+					fields[name] = s
+				} else {
+					fields[name] = fmt.Sprintf("0x%04X", code)
+				}
+			}
+		}
+		i += l
+	}
+}
@@ -0,0 +1,251 @@
+package convert
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// How strictly the Proxmark3 JSON dumps are checked before they are parsed
+type ParseMode string
+
+const (
+	ParseDefault ParseMode = ""        // accept the dumps of the official client, lowercase hex included
+	ParseStrict  ParseMode = "strict"  // reject anything the official client would not have written
+	ParseLenient ParseMode = "lenient" // repair the variations written by forks and hand-edited files
+)
+
+// Regular expressions matching the canonical block data and the block keys accepted by the lenient mode
+var (
+	canonicalBlockRe  = regexp.MustCompile(`^([0-9A-F]{2}|\?\?)*$`)
+	lenientHexRe      = regexp.MustCompile(`^[0-9A-Fa-f?]+$`)
+	lenientBlockKeyRe = regexp.MustCompile(`^(?i:block)?\s*(\d+)$`)
+)
+
+// Struct holding the parts of a Proxmark3 JSON dump the parse modes look at
+type rawProxmark3JSON struct {
+	Created  string                 `json:"Created"`
+	FileType string                 `json:"FileType"`
+	Card     map[string]interface{} `json:"Card"`
+	Blocks   map[string]string      `json:"blocks"`
+}
+
+// Function that checks a Proxmark3 JSON dump with -strict, or repairs it with -lenient, before it is parsed.
+// The repairs which may have lost data are returned as warnings
+func applyParseMode(data []byte, opts ParseOptions) ([]byte, []string, error) {
+	switch opts.Mode {
+	case ParseStrict:
+		if err := checkCanonicalJSON(data); err != nil {
+			return nil, nil, err
+		}
+	case ParseLenient:
+		normalized, warnings, err := normalizeProxmark3JSON(data)
+		if err != nil {
+			return nil, nil, err
+		}
+		opts.debug("normalized lenient JSON dump")
+		return normalized, warnings, nil
+	}
+	return data, nil, nil
+}
+
+// Function that returns the keys of the blocks object of a JSON dump in the order they are written, repeated
+// keys included, which decoding into a map hides by keeping the last one
+func rawBlockKeys(data []byte) []string {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	if tok, err := dec.Token(); err != nil || tok != json.Delim('{') {
+		return nil
+	}
+	var keys []string
+	for dec.More() {
+		tok, err := dec.Token()
+		if err != nil {
+			return keys
+		}
+		if name, _ := tok.(string); name == "blocks" || name == "Blocks" {
+			if tok, err := dec.Token(); err != nil || tok != json.Delim('{') {
+				return keys
+			}
+			for dec.More() {
+				tok, err := dec.Token()
+				if err != nil {
+					return keys
+				}
+				key, _ := tok.(string)
+				keys = append(keys, key)
+				var value json.RawMessage
+				if err := dec.Decode(&value); err != nil {
+					return keys
+				}
+			}
+			if _, err := dec.Token(); err != nil {
+				return keys
+			}
+			continue
+		}
+		var value json.RawMessage
+		if err := dec.Decode(&value); err != nil {
+			return keys
+		}
+	}
+	return keys
+}
+
+// Function that returns the block numbers given more than once among the keys of a blocks object, either under
+// the same key or under keys such as "0" and "Block 0", with the keys naming each of them
+func duplicateBlockKeys(keys []string) map[int][]string {
+	byBlock := make(map[int][]string)
+	for _, key := range keys {
+		if m := lenientBlockKeyRe.FindStringSubmatch(strings.TrimSpace(key)); m != nil {
+			n, _ := strconv.Atoi(m[1])
+			byBlock[n] = append(byBlock[n], key)
+		}
+	}
+	for n, names := range byBlock {
+		if len(names) < 2 {
+			delete(byBlock, n)
+		}
+	}
+	return byBlock
+}
+
+// Function that returns the sorted block numbers of duplicateBlockKeys, so they are reported in order
+func sortedBlocks(duplicates map[int][]string) []int {
+	blocks := make([]int, 0, len(duplicates))
+	for n := range duplicates {
+		blocks = append(blocks, n)
+	}
+	sort.Ints(blocks)
+	return blocks
+}
+
+// Function that checks that a Proxmark3 JSON dump is written the way the official client writes it:
+// Created and FileType headers, hex without whitespace in uppercase and blocks keyed by their number
+func checkCanonicalJSON(data []byte) error {
+	var dump rawProxmark3JSON
+	if err := unmarshalJSON(data, &dump); err != nil {
+		return fmt.Errorf("failed to decode Proxmark3 JSON file: %w", err)
+	}
+
+	if dump.Created != "proxmark3" {
+		return fmt.Errorf("%w: Created header must be 'proxmark3', got '%s'", ErrNotCanonical, dump.Created)
+	}
+	if dump.FileType == "" {
+		return fmt.Errorf("%w: FileType header is missing", ErrNotCanonical)
+	}
+	for name, v := range dump.Card {
+		if s, ok := v.(string); ok && (strings.ContainsAny(s, " \t\r\n") || s != strings.ToUpper(s)) {
+			return fmt.Errorf("%w: Card %s '%s' is not uppercase hex without spaces", ErrNotCanonical, name, s)
+		}
+	}
+	if duplicates := duplicateBlockKeys(rawBlockKeys(data)); len(duplicates) > 0 {
+		n := sortedBlocks(duplicates)[0]
+		return fmt.Errorf("%w: block %d is given %d times", ErrNotCanonical, n, len(duplicates[n]))
+	}
+	for key, data := range dump.Blocks {
+		if n, err := strconv.Atoi(key); err != nil || n < 0 || strconv.Itoa(n) != key {
+			return fmt.Errorf("%w: block key '%s' is not a block number", ErrNotCanonical, key)
+		}
+		if !canonicalBlockRe.MatchString(data) {
+			return fmt.Errorf("%w: block %s '%s' is not uppercase hex without spaces", ErrNotCanonical, key, data)
+		}
+	}
+	return nil
+}
+
+// Function that removes the whitespace of a hex string and turns it to uppercase, leaving other strings alone
+func normalizeHex(s string) string {
+	compact := strings.Join(strings.Fields(s), "")
+	if !lenientHexRe.MatchString(compact) {
+		return s
+	}
+	return strings.ToUpper(compact)
+}
+
+// Function that rewrites the variations found in dumps of Proxmark3 forks into the canonical form: hex with
+// whitespace or in lowercase in the Card, SectorKeys and blocks sections, missing Created and FileType headers, "Blocks" instead of "blocks" and blocks
+// keyed as "Block 0" instead of "0". A missing FileType is guessed from the fields of the Card section.
+// A block given under several keys keeps the value of the first key in sorted order, "0" before "Block 0",
+// and is reported in the returned warnings
+func normalizeProxmark3JSON(data []byte) ([]byte, []string, error) {
+	var dump map[string]interface{}
+	if err := unmarshalJSON(data, &dump); err != nil {
+		return nil, nil, fmt.Errorf("failed to decode Proxmark3 JSON file: %w", err)
+	}
+
+	var warnings []string
+	duplicates := duplicateBlockKeys(rawBlockKeys(data))
+	for _, n := range sortedBlocks(duplicates) {
+		warnings = append(warnings, fmt.Sprintf("block %d is given %d times, as '%s', only one of them is kept",
+			n, len(duplicates[n]), strings.Join(duplicates[n], "', '")))
+	}
+
+	if created, _ := dump["Created"].(string); created == "" || strings.HasPrefix(strings.ToLower(created), "proxmark3") {
+		dump["Created"] = "proxmark3"
+	}
+
+	card, _ := dump["Card"].(map[string]interface{})
+	for name, v := range card {
+		if s, ok := v.(string); ok {
+			card[name] = normalizeHex(s)
+		}
+	}
+
+	sectorKeys, _ := dump["SectorKeys"].(map[string]interface{})
+	for _, v := range sectorKeys {
+		sector, _ := v.(map[string]interface{})
+		for name, v := range sector {
+			if s, ok := v.(string); ok {
+				sector[name] = normalizeHex(s)
+			}
+		}
+	}
+
+	if _, ok := dump["blocks"]; !ok {
+		dump["blocks"] = dump["Blocks"]
+		delete(dump, "Blocks")
+	}
+	if blocks, ok := dump["blocks"].(map[string]interface{}); ok {
+		keys := make([]string, 0, len(blocks))
+		for key := range blocks {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+
+		normalized := make(map[string]interface{}, len(blocks))
+		for _, key := range keys {
+			m := lenientBlockKeyRe.FindStringSubmatch(strings.TrimSpace(key))
+			if m == nil {
+				return nil, nil, fmt.Errorf("invalid block number '%s'", key)
+			}
+			n, _ := strconv.Atoi(m[1])
+			if _, ok := normalized[strconv.Itoa(n)]; ok {
+				continue
+			}
+			v := blocks[key]
+			if s, ok := v.(string); ok {
+				v = normalizeHex(s)
+			}
+			normalized[strconv.Itoa(n)] = v
+		}
+		dump["blocks"] = normalized
+	}
+
+	if fileType, _ := dump["FileType"].(string); fileType == "" {
+		switch {
+		case card["IDm"] != nil:
+			dump["FileType"] = "felica"
+		case card["DSFID"] != nil || card["AFI"] != nil:
+			dump["FileType"] = "15693"
+		default:
+			dump["FileType"] = "mfcard"
+		}
+	}
+
+	normalized, err := json.Marshal(dump)
+	return normalized, warnings, err
+}
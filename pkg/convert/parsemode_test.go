@@ -0,0 +1,59 @@
+package convert
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+// Head of a Proxmark3 JSON dump of a Mifare Classic 1K, to be completed with the blocks object
+const mifareJSONHead = `{
+  "Created": "proxmark3",
+  "FileType": "mfcard",
+  "Card": {"UID": "11223344", "ATQA": "0004", "SAK": "08"},
+  "blocks": `
+
+func TestLenientDuplicateBlockKeys(t *testing.T) {
+	dump := mifareJSONHead + `{
+    "Block 0": "11223344440804006263646566676869",
+    "0": "11223344440804006263646566676869",
+    "1": "000102030405060708090A0B0C0D0E0F",
+    "Block 1": "FFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFF"
+  }
+}`
+	card, err := ParseWithOptions(strings.NewReader(dump), ParseOptions{Mode: ParseLenient})
+	if err != nil {
+		t.Fatalf("ParseWithOptions: %v", err)
+	}
+	mc := card.(*MifareCard)
+	if got := mc.Blocks[1].String(); got != "00 01 02 03 04 05 06 07 08 09 0A 0B 0C 0D 0E 0F" {
+		t.Errorf("block 1 = %s, want the value of key '1'", got)
+	}
+
+	var duplicates []string
+	for _, w := range mc.Warnings {
+		if strings.Contains(w, "only one of them is kept") {
+			duplicates = append(duplicates, w)
+		}
+	}
+	want := []string{
+		"block 0 is given 2 times, as 'Block 0', '0', only one of them is kept",
+		"block 1 is given 2 times, as '1', 'Block 1', only one of them is kept",
+	}
+	if strings.Join(duplicates, "\n") != strings.Join(want, "\n") {
+		t.Errorf("duplicate warnings = %q, want %q", duplicates, want)
+	}
+}
+
+func TestStrictDuplicateBlockKeys(t *testing.T) {
+	dump := mifareJSONHead + `{
+    "0": "11223344440804006263646566676869",
+    "1": "000102030405060708090A0B0C0D0E0F",
+    "1": "FFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFF"
+  }
+}`
+	_, err := ParseWithOptions(strings.NewReader(dump), ParseOptions{Mode: ParseStrict})
+	if !errors.Is(err, ErrNotCanonical) || !strings.Contains(err.Error(), "block 1 is given 2 times") {
+		t.Errorf("ParseWithOptions error = %v, want block 1 given twice", err)
+	}
+}
@@ -0,0 +1,57 @@
+package convert
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Regular expressions matching the lines printed by the Proxmark3 client for Mifare Classic cards
+var (
+	outputUIDRe   = regexp.MustCompile(`\bUID:\s*((?:[0-9A-Fa-f]{2} ?){4,10})`)
+	outputATQARe  = regexp.MustCompile(`\bATQA:\s*([0-9A-Fa-f]{2}) ?([0-9A-Fa-f]{2})\b`)
+	outputSAKRe   = regexp.MustCompile(`\bSAK:\s*([0-9A-Fa-f]{2})\b`)
+	outputBlockRe = regexp.MustCompile(`(\d+)\s*\|\s*((?:[0-9A-Fa-f?-]{2} ){15}[0-9A-Fa-f?-]{2})\s*\|`)
+)
+
+// Function that parses the terminal output of the Proxmark3 client, e.g. of `hf 14a info` followed
+// by `hf mf eview`, and returns a MifareCard struct built from the printed identification and block table
+func ParseProxmark3MifareOutput(r io.Reader, opts ParseOptions) (*MifareCard, error) {
+	var uid, atqa, sak string
+	blocks := make(map[string]string)
+
+	sc := bufio.NewScanner(r)
+	for lineNum := 1; sc.Scan(); lineNum++ {
+		line := ansiEscapeRe.ReplaceAllString(sc.Text(), "")
+
+		if m := outputBlockRe.FindStringSubmatch(line); m != nil {
+			n, err := strconv.Atoi(m[1])
+			if err != nil || n >= ClassicMaxBlocks {
+				return nil, &PositionError{Line: lineNum, Err: fmt.Errorf("invalid block number '%s'", m[1])}
+			}
+			data := strings.ReplaceAll(strings.ReplaceAll(m[2], " ", ""), "--", "??")
+			blocks[strconv.Itoa(n)] = data
+			continue
+		}
+		if m := outputUIDRe.FindStringSubmatch(line); m != nil && uid == "" {
+			uid = strings.ReplaceAll(strings.TrimSpace(m[1]), " ", "")
+		}
+		if m := outputATQARe.FindStringSubmatch(line); m != nil && atqa == "" {
+			atqa = m[1] + m[2]
+		}
+		if m := outputSAKRe.FindStringSubmatch(line); m != nil && sak == "" {
+			sak = m[1]
+		}
+	}
+	if err := sc.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read Proxmark3 client output: %w", err)
+	}
+
+	if len(blocks) == 0 {
+		return nil, ErrNoMifareBlocks
+	}
+	return newMifareCard(uid, atqa, sak, blocks, opts)
+}
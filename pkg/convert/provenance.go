@@ -0,0 +1,51 @@
+package convert
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Struct describing where a converted file comes from, written as comments into Flipper files
+type Provenance struct {
+	Source       string    // name of the dump the file was converted from
+	SourceSHA256 string    // SHA-256 of the dump in hex, empty when unknown
+	Tool         string    // name and version of the converter
+	Time         time.Time // time of the conversion
+}
+
+// Function that formats the provenance as Flipper file comments
+func (p *Provenance) comments() string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "# Converted from: %s\n", p.Source)
+	if p.SourceSHA256 != "" {
+		fmt.Fprintf(&sb, "# Source SHA-256: %s\n", p.SourceSHA256)
+	}
+	if p.Tool != "" {
+		fmt.Fprintf(&sb, "# Converted by: %s\n", p.Tool)
+	}
+	if !p.Time.IsZero() {
+		fmt.Fprintf(&sb, "# Converted at: %s\n", p.Time.UTC().Format(time.RFC3339))
+	}
+	return sb.String()
+}
+
+// Function that inserts the provenance comments after the Version line of a Flipper file, keeping
+// the Filetype and Version header where the Flipper expects it
+func insertProvenance(file []byte, p *Provenance) []byte {
+	at := 0
+	for i := 0; i < 2; i++ {
+		n := bytes.IndexByte(file[at:], '\n')
+		if n < 0 {
+			at = len(file)
+			break
+		}
+		at += n + 1
+	}
+
+	out := make([]byte, 0, len(file)+256)
+	out = append(out, file[:at]...)
+	out = append(out, p.comments()...)
+	return append(out, file[at:]...)
+}
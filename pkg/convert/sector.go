@@ -0,0 +1,42 @@
+package convert
+
+// Number of sectors made of 4 blocks at the start of every Mifare Classic card;
+// the sectors after them, found on 4K cards only, are made of 16 blocks
+const classicSmallSectors = 32
+
+// Function that returns the number of sectors of a Mifare Classic card with the given number of blocks
+func ClassicSectorCount(blocksNum int) int {
+	if blocksNum <= classicSmallSectors*4 {
+		return blocksNum / 4
+	}
+	return classicSmallSectors + (blocksNum-classicSmallSectors*4)/16
+}
+
+// Function that returns the index of the first block of a Mifare Classic sector
+func ClassicSectorFirstBlock(sector int) int {
+	if sector < classicSmallSectors {
+		return sector * 4
+	}
+	return classicSmallSectors*4 + (sector-classicSmallSectors)*16
+}
+
+// Function that returns the number of blocks of a Mifare Classic sector
+func ClassicSectorBlocks(sector int) int {
+	if sector < classicSmallSectors {
+		return 4
+	}
+	return 16
+}
+
+// Function that returns the index of the trailer block of a Mifare Classic sector
+func ClassicSectorTrailer(sector int) int {
+	return ClassicSectorFirstBlock(sector) + ClassicSectorBlocks(sector) - 1
+}
+
+// Function that returns the sector a Mifare Classic block belongs to
+func ClassicBlockSector(block int) int {
+	if block < classicSmallSectors*4 {
+		return block / 4
+	}
+	return classicSmallSectors + (block-classicSmallSectors*4)/16
+}
@@ -0,0 +1,84 @@
+package convert
+
+import (
+	"bytes"
+	"fmt"
+	"strconv"
+)
+
+// Struct representing a sector of the SectorKeys section the Iceman Proxmark3 client adds to Mifare dumps,
+// the access conditions text is left out since it is decoded again from the access bits
+type sectorKeysJSON struct {
+	KeyA             string `json:"KeyA"`
+	KeyB             string `json:"KeyB"`
+	AccessConditions string `json:"AccessConditions"`
+}
+
+// Function that reports whether all bytes of data are zero, the way some clients mask keys they could not read
+func isZero(data []byte) bool {
+	for _, b := range data {
+		if b != 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// Function that cross-checks the SectorKeys section of an Iceman dump with the sector trailers: trailer bytes
+// that are unknown or zeroed out are filled in from the section, differing bytes are reported as warnings
+func (c *MifareCard) applySectorKeys(sectorKeys map[string]sectorKeysJSON) error {
+	sectors := ClassicSectorCount(len(c.Blocks))
+	for sectorStr := range sectorKeys {
+		if s, err := strconv.Atoi(sectorStr); err != nil || s < 0 || s >= sectors {
+			return fmt.Errorf("invalid SectorKeys sector '%s'", sectorStr)
+		}
+	}
+
+	filled := 0
+	for s := 0; s < sectors; s++ {
+		sk, ok := sectorKeys[strconv.Itoa(s)]
+		if !ok {
+			continue
+		}
+
+		trailer := &c.Blocks[ClassicSectorTrailer(s)]
+		changed := false
+		for _, f := range []struct {
+			name   string
+			value  string
+			offset int
+			size   int
+		}{
+			{"KeyA", sk.KeyA, 0, ClassicKeySize},
+			{"AccessConditions", sk.AccessConditions, 6, 4},
+			{"KeyB", sk.KeyB, 10, ClassicKeySize},
+		} {
+			if f.value == "" {
+				continue
+			}
+			value, err := DecodeHexData(f.value)
+			if err != nil {
+				return &FieldError{Field: fmt.Sprintf("SectorKeys %d %s", s, f.name), Err: err}
+			}
+			if len(value) != f.size {
+				return &FieldError{Field: fmt.Sprintf("SectorKeys %d %s", s, f.name), Err: fmt.Errorf("expecting %d bytes, got %d", f.size, len(value))}
+			}
+
+			current := knownBytes(*trailer, f.offset, f.size)
+			switch {
+			case current == nil || isZero(current) && !isZero(value):
+				setKnown(trailer, f.offset, value)
+				changed = true
+			case !bytes.Equal(current, value):
+				c.Warnings = append(c.Warnings, fmt.Sprintf("sector %d: SectorKeys %s %s does not match %s in the trailer", s, f.name, value, current))
+			}
+		}
+		if changed {
+			filled++
+		}
+	}
+	if filled > 0 {
+		c.Warnings = append(c.Warnings, fmt.Sprintf("filled the masked key bytes of %d sector trailers from the SectorKeys section", filled))
+	}
+	return nil
+}
@@ -0,0 +1,119 @@
+package convert
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math/bits"
+)
+
+// Error returned when a key cannot be encoded for a T5577, to be matched with errors.Is
+var ErrNoT5577Encoding = errors.New("cannot encode key for T5577")
+
+// T5577 configuration blocks (block 0) of the emulated credentials, as the Proxmark3 clone commands write them
+const (
+	t5577ConfigEM4100 = 0x00148040 // ASK/Manchester, RF/64, 2 data blocks
+	t5577ConfigHID    = 0x00107060 // FSK2a, RF/50, 3 data blocks
+	t5577ConfigIndala = 0x00081040 // PSK1, RF/32, 2 data blocks
+)
+
+// Output formats of the T5577 programming data
+type T5577Format string
+
+const (
+	T5577Proxmark3 T5577Format = "pm3"    // `lf t55xx write` commands for the Proxmark3 client
+	T5577Blocks    T5577Format = "blocks" // one "Block N: XXXXXXXX" line per block
+)
+
+// Function that computes the T5577 blocks which make the chip emulate the LF key, starting with
+// the configuration block 0
+func T5577Data(c *LFCard) ([]uint32, error) {
+	switch c.KeyType {
+	case "EM4100":
+		if len(c.Data) != 5 {
+			return nil, fmt.Errorf("%w: EM4100 ID must be 5 bytes, got %d", ErrNoT5577Encoding, len(c.Data))
+		}
+		frame := em4100Frame(c.Data)
+		return []uint32{t5577ConfigEM4100, uint32(frame >> 32), uint32(frame)}, nil
+
+	case "H10301":
+		if len(c.Data) != 3 {
+			return nil, fmt.Errorf("%w: H10301 data must be 3 bytes, got %d", ErrNoT5577Encoding, len(c.Data))
+		}
+		w := uint64(c.Data[0])<<17 | uint64(c.Data[1])<<9 | uint64(c.Data[2])<<1
+		if bits.OnesCount64(w>>13&0xFFF)%2 == 1 {
+			w |= 1 << 25
+		}
+		if bits.OnesCount64(w>>1&0xFFF)%2 == 0 {
+			w |= 1
+		}
+		// 26-bit format marker followed by the start sentinel of the Wiegand data
+		return hidBlocks(1<<37 | 1<<26 | w), nil
+
+	case "HIDProx":
+		var v uint64
+		for _, b := range c.Data {
+			v = v<<8 | uint64(b)
+		}
+		if v>>44 != 0 {
+			return nil, fmt.Errorf("%w: HID frame longer than 44 bits", ErrNoT5577Encoding)
+		}
+		return hidBlocks(v), nil
+
+	case "Indala26":
+		if len(c.Raw) != 8 {
+			return nil, fmt.Errorf("%w: Indala26 needs the 64-bit raw frame read by the Proxmark3", ErrNoT5577Encoding)
+		}
+		return []uint32{t5577ConfigIndala, binary.BigEndian.Uint32(c.Raw[0:4]), binary.BigEndian.Uint32(c.Raw[4:8])}, nil
+	}
+	return nil, fmt.Errorf("%w: unsupported key type '%s'", ErrNoT5577Encoding, c.KeyType)
+}
+
+// Function that builds the 64-bit EM4100 frame: 9 header bits, 10 rows of 4 data bits with even parity,
+// 4 column parity bits and a stop bit
+func em4100Frame(id HexData) uint64 {
+	frame := uint64(0x1FF)
+	var columns uint64
+	for _, b := range id {
+		for _, nibble := range []byte{b >> 4, b & 0x0F} {
+			frame = frame<<5 | uint64(nibble)<<1 | uint64(bits.OnesCount8(nibble)%2)
+			columns ^= uint64(nibble)
+		}
+	}
+	return frame<<5 | columns<<1
+}
+
+// Function that builds the HID Prox T5577 blocks: the 0x1D preamble followed by the 44-bit frame
+// Manchester encoded, a 1 bit sent as 10 and a 0 bit as 01
+func hidBlocks(frame uint64) []uint32 {
+	var encoded [12]byte
+	encoded[0] = 0x1D
+	for i := 0; i < 44; i++ {
+		pos := 8 + 2*i
+		if frame>>uint(43-i)&1 == 0 {
+			pos++
+		}
+		encoded[pos/8] |= 1 << (7 - uint(pos%8))
+	}
+	return []uint32{t5577ConfigHID, binary.BigEndian.Uint32(encoded[0:4]), binary.BigEndian.Uint32(encoded[4:8]), binary.BigEndian.Uint32(encoded[8:12])}
+}
+
+// Function that writes T5577 blocks in the given format
+func WriteT5577(w io.Writer, blocks []uint32, format T5577Format) error {
+	for i, b := range blocks {
+		var err error
+		switch format {
+		case T5577Proxmark3:
+			_, err = fmt.Fprintf(w, "lf t55xx write -b %d -d %08X\n", i, b)
+		case T5577Blocks:
+			_, err = fmt.Fprintf(w, "Block %d: %08X\n", i, b)
+		default:
+			return fmt.Errorf("unsupported T5577 output format '%s'", format)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
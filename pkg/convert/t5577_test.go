@@ -0,0 +1,93 @@
+package convert
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestT5577Data(t *testing.T) {
+	tests := []struct {
+		name   string
+		output string
+		want   []uint32
+	}{
+		{"EM410x", em410xReaderOutput, []uint32{0x00148040, 0xFF83C033, 0x22A646E4}},
+		{"HID H10301", hidReaderOutput, []uint32{0x00107060, 0x1D555955, 0x5569A9A5, 0x55A59569}},
+		{"HID Prox raw", hidProxReaderOutput, []uint32{0x00107060, 0x1D555955, 0x5569A9A5, 0x55A59569}},
+		{"Indala", indalaReaderOutput, []uint32{0x00081040, 0xA0000000, 0xC2C436C1}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, err := ParseProxmark3LF(strings.NewReader(tt.output))
+			if err != nil {
+				t.Fatalf("ParseProxmark3LF: %v", err)
+			}
+			got, err := T5577Data(c)
+			if err != nil {
+				t.Fatalf("T5577Data: %v", err)
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("T5577Data = %08X, want %08X", got, tt.want)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Errorf("block %d = %08X, want %08X", i, got[i], tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestEM4100FrameAllZero(t *testing.T) {
+	// only the 9 header bits are set when every nibble and parity bit is 0
+	if got := em4100Frame(HexData{0, 0, 0, 0, 0}); got != 0xFF80000000000000 {
+		t.Errorf("em4100Frame = %016X, want FF80000000000000", got)
+	}
+}
+
+func TestT5577DataErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		card *LFCard
+	}{
+		{"EM4100 short ID", &LFCard{KeyType: "EM4100", Data: HexData{0x0F, 0x03}}},
+		{"H10301 long data", &LFCard{KeyType: "H10301", Data: HexData{1, 2, 3, 4}}},
+		{"HID frame over 44 bits", &LFCard{KeyType: "HIDProx", Data: HexData{0x10, 0, 0, 0, 0, 0}}},
+		{"Indala26 without raw frame", &LFCard{KeyType: "Indala26", Data: HexData{0x85, 0x88, 0x6D, 0x80}}},
+		{"unsupported key type", &LFCard{KeyType: "Paradox", Data: HexData{1, 2, 3}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := T5577Data(tt.card); !errors.Is(err, ErrNoT5577Encoding) {
+				t.Errorf("T5577Data error = %v, want %v", err, ErrNoT5577Encoding)
+			}
+		})
+	}
+}
+
+func TestWriteT5577(t *testing.T) {
+	blocks := []uint32{0x00148040, 0xFF83C033, 0x22A646E4}
+	tests := []struct {
+		format T5577Format
+		want   string
+	}{
+		{T5577Proxmark3, "lf t55xx write -b 0 -d 00148040\nlf t55xx write -b 1 -d FF83C033\nlf t55xx write -b 2 -d 22A646E4\n"},
+		{T5577Blocks, "Block 0: 00148040\nBlock 1: FF83C033\nBlock 2: 22A646E4\n"},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.format), func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := WriteT5577(&buf, blocks, tt.format); err != nil {
+				t.Fatalf("WriteT5577: %v", err)
+			}
+			if got := buf.String(); got != tt.want {
+				t.Errorf("WriteT5577 wrote\n%s\nwant\n%s", got, tt.want)
+			}
+		})
+	}
+	if err := WriteT5577(&bytes.Buffer{}, blocks, "hex"); err == nil {
+		t.Error("WriteT5577 accepted an unknown format")
+	}
+}
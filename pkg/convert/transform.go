@@ -0,0 +1,66 @@
+package convert
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Sector number selecting every sector of the card in SetKeyA and SetKeyB
+const AllSectors = -1
+
+// Function that changes the UID of the card, rewriting it in block 0 along with its BCC for 4-byte UIDs.
+// The new UID must have the length of the current one since the rest of block 0 depends on it
+func (c *MifareCard) SetUID(uid HexData) error {
+	if len(uid) != len(c.UID) {
+		return fmt.Errorf("new UID must be %d bytes like the current one, got %d", len(c.UID), len(uid))
+	}
+	if len(c.Blocks) == 0 {
+		return &BlockError{Block: 0, Err: errors.New("missing")}
+	}
+
+	block0 := &c.Blocks[0]
+	setKnown(block0, 0, uid)
+	if len(uid) == 4 {
+		setKnown(block0, 4, HexData{bcc(uid)})
+	}
+	c.UID = append(HexData(nil), uid...)
+	return nil
+}
+
+// Function that replaces Key A in the trailer of a sector, or of every sector with AllSectors
+func (c *MifareCard) SetKeyA(sector int, key HexData) error {
+	return c.setKey(sector, 0, key)
+}
+
+// Function that replaces Key B in the trailer of a sector, or of every sector with AllSectors
+func (c *MifareCard) SetKeyB(sector int, key HexData) error {
+	return c.setKey(sector, 10, key)
+}
+
+// Function that writes a key at the given offset of one or every sector trailer
+func (c *MifareCard) setKey(sector, offset int, key HexData) error {
+	if len(key) != ClassicKeySize {
+		return fmt.Errorf("key must be %d bytes, got %d", ClassicKeySize, len(key))
+	}
+	sectors := ClassicSectorCount(len(c.Blocks))
+	if sector != AllSectors && (sector < 0 || sector >= sectors) {
+		return fmt.Errorf("sector %d out of range, the card has %d sectors", sector, sectors)
+	}
+
+	for s := 0; s < sectors; s++ {
+		if sector == AllSectors || s == sector {
+			setKnown(&c.Blocks[ClassicSectorTrailer(s)], offset, key)
+		}
+	}
+	return nil
+}
+
+// Function that overwrites the bytes of a block starting at offset, marking them as known
+func setKnown(b *Block, offset int, data []byte) {
+	copy(b.Data[offset:], data)
+	for i := range data {
+		if b.Unknown != nil && offset+i < len(b.Unknown) {
+			b.Unknown[offset+i] = false
+		}
+	}
+}
@@ -0,0 +1,105 @@
+package convert
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strconv"
+)
+
+// Size in bytes of a Mifare Ultralight / NTAG page
+const UltralightPageSize = 4
+
+// Struct representing the data structure of a Mifare Ultralight or NTAG card
+type UltralightCard struct {
+	UID     HexData
+	Version HexData // GET_VERSION response, empty for cards without it
+	Pages   []Block
+}
+
+// Function that parses a Proxmark3 JSON dump of a Mifare Ultralight or NTAG card, checked or repaired
+// according to the parse mode of the options like the other JSON dumps
+func ParseProxmark3UltralightJSONWithOptions(r io.Reader, opts ParseOptions) (*UltralightCard, error) {
+	data, err := ReadDump(r)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read dump: %w", err)
+	}
+	data, warnings, err := applyParseMode(data, opts)
+	if err != nil {
+		return nil, err
+	}
+	opts.warn(warnings)
+	return ParseProxmark3UltralightJSON(bytes.NewReader(data))
+}
+
+// Function that parses a Proxmark3 JSON dump of a Mifare Ultralight or NTAG card (`hf mfu dump`)
+func ParseProxmark3UltralightJSON(r io.Reader) (*UltralightCard, error) {
+	var proxmark3JSON struct {
+		Created  string `json:"Created"`
+		FileType string `json:"FileType"`
+		Card     struct {
+			UID     string `json:"UID"`
+			Version string `json:"Version"`
+		} `json:"Card"`
+		Blocks map[string]string `json:"blocks"`
+	}
+
+	if err := decodeJSON(r, &proxmark3JSON); err != nil {
+		return nil, fmt.Errorf("failed to decode Proxmark3 JSON file: %w", err)
+	}
+
+	if proxmark3JSON.Created != "proxmark3" {
+		return nil, ErrNotProxmark3
+	}
+
+	if proxmark3JSON.FileType != "mfu" {
+		return nil, ErrUnsupportedFileType
+	}
+
+	uid, err := DecodeHexData(proxmark3JSON.Card.UID)
+	if err != nil {
+		return nil, &FieldError{Field: "UID", Err: err}
+	}
+	if err := checkUIDSize(uid); err != nil {
+		return nil, err
+	}
+	version, err := DecodeHexData(proxmark3JSON.Card.Version)
+	if err != nil {
+		return nil, &FieldError{Field: "Version", Err: err}
+	}
+
+	// pages are numbered from 0 up to the last page of the dump, those missing from it are unknown
+	decoded := make(map[int]Block, len(proxmark3JSON.Blocks))
+	lastPage := -1
+	for pageNumStr, pageData := range proxmark3JSON.Blocks {
+		i, err := strconv.Atoi(pageNumStr)
+		if err != nil || i < 0 {
+			return nil, fmt.Errorf("invalid page number '%s'", pageNumStr)
+		}
+		if i >= UltralightMaxPages {
+			return nil, &BlockError{Block: i, Err: fmt.Errorf("past the last page %d an Ultralight or NTAG card can address", UltralightMaxPages-1)}
+		}
+		page, err := DecodeBlock(pageData)
+		if err != nil {
+			return nil, &BlockError{Block: i, Err: err}
+		}
+		if len(page.Data) != UltralightPageSize {
+			return nil, &BlockError{Block: i, Err: fmt.Errorf("expecting %d bytes, got %d", UltralightPageSize, len(page.Data))}
+		}
+		decoded[i] = page
+		if i > lastPage {
+			lastPage = i
+		}
+	}
+
+	pages := make([]Block, lastPage+1)
+	for i := range pages {
+		if page, ok := decoded[i]; ok {
+			pages[i] = page
+		} else {
+			pages[i] = UnknownBlock(UltralightPageSize)
+		}
+	}
+
+	return &UltralightCard{UID: uid, Version: version, Pages: pages}, nil
+}
@@ -0,0 +1,35 @@
+package convert
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseProxmark3UltralightJSONSparse(t *testing.T) {
+	dump := `{
+  "Created": "proxmark3",
+  "FileType": "mfu",
+  "Card": {"UID": "04A1B2C3D4E5F6", "Version": "0004040201000F03"},
+  "blocks": {"0": "04A1B2BF", "1": "C3D4E5F6", "44": "000000BD"}
+}`
+	c, err := ParseProxmark3UltralightJSON(strings.NewReader(dump))
+	if err != nil {
+		t.Fatalf("ParseProxmark3UltralightJSON: %v", err)
+	}
+	if len(c.Pages) != 45 {
+		t.Fatalf("got %d pages, want 45", len(c.Pages))
+	}
+	if got := c.Pages[44].String(); got != "00 00 00 BD" {
+		t.Errorf("page 44 = %s, want 00 00 00 BD", got)
+	}
+	if c.Pages[2].IsComplete() {
+		t.Errorf("page 2 missing from the dump is not unknown: %s", c.Pages[2])
+	}
+
+	for _, blocks := range []string{`{"256": "00000000"}`, `{"x": "00000000"}`, `{"3": "0000"}`} {
+		dump := `{"Created": "proxmark3", "FileType": "mfu", "Card": {"UID": "04A1B2C3D4E5F6"}, "blocks": ` + blocks + `}`
+		if _, err := ParseProxmark3UltralightJSON(strings.NewReader(dump)); err == nil {
+			t.Errorf("ParseProxmark3UltralightJSON accepted blocks %s", blocks)
+		}
+	}
+}
@@ -0,0 +1,84 @@
+package convert
+
+import "fmt"
+
+// Struct describing a structural problem found in a dump
+type Issue struct {
+	Check   string // name of the check that found the problem
+	Block   int    // block the problem was found in, -1 when it concerns the whole card
+	Message string
+}
+
+// String method for Issue type to print the problem with its location
+func (i Issue) String() string {
+	if i.Block < 0 {
+		return fmt.Sprintf("%s: %s", i.Check, i.Message)
+	}
+	return fmt.Sprintf("%s: block %d: %s", i.Check, i.Block, i.Message)
+}
+
+// Function that checks a Mifare Classic dump for structural correctness: block 0 BCC, sector trailer
+// access bits, ATQA/SAK plausibility for the card size and suspicious UIDs
+func ValidateMifare(c *MifareCard) []Issue {
+	var issues []Issue
+	add := func(check string, block int, format string, args ...interface{}) {
+		issues = append(issues, Issue{Check: check, Block: block, Message: fmt.Sprintf(format, args...)})
+	}
+
+	for _, w := range c.Warnings {
+		add("parse", -1, "%s", w)
+	}
+
+	// block 0 BCC, only stored for 4-byte UIDs
+	if len(c.UID) == 4 && len(c.Blocks) > 0 {
+		b0 := c.Blocks[0]
+		if b0.IsComplete() && len(b0.Data) > 4 && bcc(b0.Data[:4]) != b0.Data[4] {
+			add("bcc", 0, "BCC %02X does not match the UID, expecting %02X", b0.Data[4], bcc(b0.Data[:4]))
+		}
+	}
+
+	// sector trailer access bits
+	if _, err := classicTypeName(len(c.Blocks)); err != nil {
+		add("size", -1, "%v", err)
+	} else {
+		for s := 0; s < ClassicSectorCount(len(c.Blocks)); s++ {
+			trailer := ClassicSectorTrailer(s)
+			if c.Blocks[trailer].IsComplete() {
+				if _, err := DecodeAccessBits(c.Blocks[trailer]); err != nil {
+					add("access-bits", trailer, "sector %d: %v", s, err)
+				}
+			}
+		}
+	}
+
+	// ATQA and SAK against the card size and UID length
+	if sakBlocks := classicBlocksBySAK(c.SAK); sakBlocks == 0 {
+		add("sak", -1, "SAK %s is not a Mifare Classic SAK", c.SAK)
+	} else if sakBlocks != len(c.Blocks) {
+		add("sak", -1, "SAK %s is for a %d-block card but the dump has %d blocks", c.SAK, sakBlocks, len(c.Blocks))
+	}
+	if len(c.ATQA) == 2 {
+		uidLens := [...]int{4, 7, 10, 0}
+		if want := uidLens[c.ATQA[0]>>6]; want != len(c.UID) {
+			add("atqa", -1, "ATQA %s announces a %d-byte UID but the UID has %d bytes", c.ATQA, want, len(c.UID))
+		}
+	}
+
+	// UIDs which cannot come from a genuine card
+	if len(c.UID) > 0 {
+		same := true
+		for _, b := range c.UID[1:] {
+			if b != c.UID[0] {
+				same = false
+			}
+		}
+		if same {
+			add("uid", -1, "UID %s repeats the same byte", c.UID)
+		}
+		if c.UID[0] == 0x88 {
+			add("uid", -1, "UID %s starts with the cascade tag 88", c.UID)
+		}
+	}
+
+	return issues
+}
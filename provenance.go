@@ -0,0 +1,106 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/dimchansky/proxmark3-to-flipper/pkg/convert"
+)
+
+// Function that computes the SHA-256 of a file in hex
+func fileSHA256(fileName string) (string, error) {
+	f, err := os.Open(fileName)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// Function that describes where a converted file comes from: the base name and SHA-256 of the dump,
+// nothing but "stdin" when it was read from standard input
+func newProvenance(input string) (*convert.Provenance, error) {
+	p := &convert.Provenance{
+		Source: "stdin",
+		Tool:   "proxmark3-to-flipper " + Version,
+		Time:   time.Now(),
+	}
+	if input == stdioFileName {
+		return p, nil
+	}
+
+	sum, err := fileSHA256(input)
+	if err != nil {
+		return nil, fmt.Errorf("failed to hash dump file '%s': %w", input, err)
+	}
+	p.Source = filepath.Base(input)
+	p.SourceSHA256 = sum
+	return p, nil
+}
+
+// Function that writes the sidecar checksum file of an output, in the format read by `sha256sum -c`.
+// An existing checksum file is only replaced with force
+func writeChecksumFile(output string, force bool) (string, error) {
+	sum, err := fileSHA256(output)
+	if err != nil {
+		return "", fmt.Errorf("failed to hash output file '%s': %w", output, err)
+	}
+	name := output + ".sha256"
+	err = writeFileAtomic(name, "checksum file", force, func(w io.Writer) error {
+		_, err := fmt.Fprintf(w, "%s  %s\n", sum, filepath.Base(output))
+		return err
+	})
+	if err != nil {
+		return "", err
+	}
+	return name, nil
+}
+
+// Function that writes the output of a conversion, rendered through the -template or with the provenance
+// comments, and the checksum file selected on the command line, once the files written with it are checked
+func writeConvertedFile(cfg *config, input, output string, c convert.Card) error {
+	if err := checkCompanionFiles(cfg, output, c); err != nil {
+		return err
+	}
+	if cfg.template != nil {
+		if err := writeTemplateFile(cfg, input, output, c); err != nil {
+			return err
+		}
+		logger.Info("wrote file", "file", output, "template", cfg.Template)
+		return writeChecksum(cfg, output)
+	}
+
+	opts := cfg.writeOptions()
+	if cfg.Provenance {
+		p, err := newProvenance(input)
+		if err != nil {
+			return err
+		}
+		opts.Provenance = p
+	}
+
+	if err := writeOutputFile(output, c, opts, cfg.Force); err != nil {
+		return err
+	}
+	logger.Info("wrote file", "file", output, "format", opts.Format, "nfc_version", opts.NFCVersion)
+	return writeChecksum(cfg, output)
+}
+
+// Function that writes the checksum file of an output when -sha256 is selected
+func writeChecksum(cfg *config, output string) error {
+	if !cfg.Checksum || output == stdioFileName {
+		return nil
+	}
+	_, err := writeChecksumFile(output, cfg.Force)
+	return err
+}
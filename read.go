@@ -0,0 +1,113 @@
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+
+	"github.com/dimchansky/proxmark3-to-flipper/internal/serialport"
+	"github.com/dimchansky/proxmark3-to-flipper/pkg/convert"
+)
+
+// Proxmark3 client commands run by the read mode: identify the card, recover the keys and dump
+// it into the emulator memory, then print the emulator memory as a block table
+const defaultReadCommands = "hf 14a info; hf mf autopwn; hf mf eview"
+
+// Function that runs the read mode: dumps the card on a connected Proxmark3 through its client and
+// writes the Flipper file straight from the client output, without an intermediate dump file
+func runRead(args []string) error {
+	var (
+		cfg      config
+		port     string
+		client   string
+		commands string
+	)
+	fs := flag.NewFlagSet("read", flag.ExitOnError)
+	fs.StringVar(&cfg.OutputFile, "o", "", "output Flipper file in NFC format or Proxmark3 dump (see -output-format), '-' for stdout")
+	fs.StringVar(&cfg.InputFile, "i", "", "ingest saved Proxmark3 client output instead of running the client, '-' for stdin")
+	fs.StringVar(&port, "port", "", "serial port of the Proxmark3, detected when empty")
+	fs.StringVar(&client, "client", "proxmark3", "Proxmark3 client executable")
+	fs.StringVar(&commands, "cmd", defaultReadCommands, "Proxmark3 client commands printing the card identification and block table")
+	fs.IntVar(&cfg.FormatVersion, "format-version", convert.NFCFormatLatest, "Flipper NFC file format version to write (2, 3 or 4)")
+	fs.StringVar(&cfg.OutputFormat, "output-format", "", "output format: "+outputFormatList()+", by default chosen by the output file extension")
+	fs.BoolVar(&cfg.SwapATQA, "swap-atqa", false, "swap the ATQA bytes of the card instead of detecting their order")
+	fs.BoolVar(&cfg.Verbose, "v", false, "log what is parsed and written to standard error")
+	fs.BoolVar(&cfg.Debug, "vv", false, "also log the decisions of the parser: detected format, ATQA byte order, card size, missing blocks")
+	fs.BoolVar(&cfg.Quiet, "quiet", false, "only print errors, no warnings or hints")
+	fs.BoolVar(&cfg.Force, "f", false, "overwrite existing output files, which are left untouched otherwise")
+	fs.BoolVar(&cfg.Force, "force", false, "same as -f")
+	fs.Usage = func() {
+		_, _ = fmt.Fprintf(fs.Output(), "Usage of %s read [flags] [CLIENT_OUTPUT]:\n", os.Args[0])
+		fs.PrintDefaults()
+	}
+	usage = fs.Usage
+	if err := applyDefaults(fs); err != nil {
+		return err
+	}
+	_ = fs.Parse(args)
+	if err := cfg.checkOutputFormat(); err != nil {
+		return err
+	}
+	if err := cfg.applyLogLevel(); err != nil {
+		return err
+	}
+
+	var err error
+	if cfg.InputFile, err = inputArg(fs, cfg.InputFile); err != nil {
+		return err
+	}
+	if cfg.OutputFile == "" {
+		return usageError("please provide output Flipper file in NFC format")
+	}
+
+	var output io.Reader
+	switch cfg.InputFile {
+	case "":
+		out, err := runProxmark3Client(client, port, commands)
+		if err != nil {
+			return err
+		}
+		output = out
+	case stdioFileName:
+		output = os.Stdin
+	default:
+		f, err := os.Open(cfg.InputFile)
+		if err != nil {
+			return fmt.Errorf("failed to open Proxmark3 client output '%s': %w", cfg.InputFile, err)
+		}
+		defer f.Close()
+		output = f
+	}
+
+	card, err := convert.ParseProxmark3MifareOutput(output, cfg.parseOptions())
+	if err != nil {
+		return err
+	}
+	reportCard("proxmark3", card, cfg.writeOptions())
+
+	return writeOutputFile(cfg.OutputFile, card, cfg.writeOptions(), cfg.Force)
+}
+
+// Function that runs the Proxmark3 client on the port and returns its output, echoing it to stderr
+func runProxmark3Client(client, port, commands string) (io.Reader, error) {
+	if port == "" {
+		p, err := serialport.Detect(serialport.Proxmark3)
+		if err != nil {
+			return nil, fmt.Errorf("cannot detect the Proxmark3 port, please provide -port: %w", err)
+		}
+		port = p
+		_, _ = fmt.Fprintf(os.Stderr, "using Proxmark3 on %s\n", port)
+	}
+
+	var out bytes.Buffer
+	cmd := exec.Command(client, "-p", port, "-c", commands)
+	cmd.Stdout = io.MultiWriter(&out, os.Stderr)
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("failed to run Proxmark3 client '%s': %w", client, err)
+	}
+	return &out, nil
+}
@@ -0,0 +1,108 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+
+	"github.com/dimchansky/proxmark3-to-flipper/pkg/convert"
+)
+
+// Name of the JSON conversion report format
+const reportJSON = "json"
+
+// Struct representing the machine-readable summary of a single conversion
+type conversionReport struct {
+	Input         string                 `json:"input"`
+	Output        string                 `json:"output,omitempty"`
+	CacheFiles    []string               `json:"cache_files,omitempty"`
+	CardType      string                 `json:"card_type,omitempty"`
+	UID           string                 `json:"uid,omitempty"`
+	Data          string                 `json:"data,omitempty"`
+	Size          int                    `json:"size"`
+	Blocks        int                    `json:"blocks"`
+	KnownBlocks   int                    `json:"known_blocks"`
+	UnknownBlocks int                    `json:"unknown_blocks"`
+	ValueBlocks   []convert.ValueBlock   `json:"value_blocks,omitempty"`
+	Access        []convert.SectorAccess `json:"access_conditions,omitempty"`
+	Warnings      []string               `json:"warnings,omitempty"`
+	CloneHints    []string               `json:"clone_hints,omitempty"`
+	Error         string                 `json:"error,omitempty"`
+}
+
+// Struct representing the machine-readable summary of a batch conversion
+type batchReport struct {
+	Files     []conversionReport `json:"files"`
+	Converted int                `json:"converted"`
+	Failed    int                `json:"failed"`
+}
+
+// Function that builds the report of a conversion from its outcome
+func newConversionReport(res conversionResult) conversionReport {
+	r := conversionReport{Input: res.Input, CacheFiles: res.CacheFiles}
+	if res.Err != nil {
+		r.Error = res.Err.Error()
+	} else {
+		r.Output = res.Output
+	}
+
+	switch c := res.Card.(type) {
+	case *convert.MifareCard:
+		incomplete := len(c.IncompleteBlocks())
+		r.CardType = c.TypeName()
+		r.UID = c.UID.String()
+		r.Size = len(c.Blocks) * convert.ClassicBlockSize
+		r.Blocks = len(c.Blocks)
+		r.KnownBlocks = len(c.Blocks) - incomplete
+		r.UnknownBlocks = incomplete
+		r.ValueBlocks = c.ValueBlocks()
+		r.Access = c.AccessConditions()
+		r.Warnings = c.Warnings
+		r.CloneHints = c.CloneHints()
+	case *convert.FelicaCard:
+		r.CardType = "FeliCa Lite-S"
+		r.UID = c.IDm.String()
+		r.Size = len(c.Blocks) * convert.FelicaBlockSize
+		r.Blocks = len(c.Blocks)
+		for _, b := range c.Blocks {
+			if b.IsComplete() {
+				r.KnownBlocks++
+			} else {
+				r.UnknownBlocks++
+			}
+		}
+	case *convert.ISO15693Card:
+		r.CardType = "ISO 15693"
+		if c.IsSLIX() {
+			r.CardType = "ICODE SLIX"
+		}
+		r.UID = c.UID.String()
+		r.Size = len(c.Blocks) * c.BlockSize
+		r.Blocks = len(c.Blocks)
+		for _, b := range c.Blocks {
+			if b.IsComplete() {
+				r.KnownBlocks++
+			} else {
+				r.UnknownBlocks++
+			}
+		}
+	case *convert.BankCard:
+		r.CardType = "EMV bank card"
+		if c.Name != "" {
+			r.CardType += " (" + c.Name + ")"
+		}
+		r.UID = c.UID.String()
+		r.Data = c.MaskedNumber()
+		r.Warnings = c.Warnings
+	case *convert.LFCard:
+		r.CardType = c.KeyType
+		r.Data = c.Data.String()
+	}
+	return r
+}
+
+// Function that prints a conversion report as indented JSON to standard output
+func printReport(report interface{}) error {
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	return enc.Encode(report)
+}
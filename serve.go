@@ -0,0 +1,332 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/dimchansky/proxmark3-to-flipper/pkg/convert"
+)
+
+// Function that runs the serve mode: an HTTP service converting and validating the dumps posted to it,
+// for labs running the converter for a web dashboard or other internal tools
+func runServe(args []string) error {
+	var (
+		cfg     config
+		listen  string
+		maxSize int64
+		timeout time.Duration
+	)
+	fs := flag.NewFlagSet("serve", flag.ExitOnError)
+	fs.StringVar(&listen, "listen", "localhost:8080", "address the HTTP service listens on")
+	fs.Int64Var(&maxSize, "max-size", convert.MaxDumpSize, "largest dump accepted in a request, in bytes")
+	fs.IntVar(&cfg.Jobs, "jobs", runtime.NumCPU(), "number of requests handled in parallel, the others wait for a free slot")
+	fs.DurationVar(&timeout, "timeout", 30*time.Second, "time allowed to read a request and to write its response")
+	fs.BoolVar(&cfg.Verbose, "v", false, "log every request to standard error")
+	fs.BoolVar(&cfg.Debug, "vv", false, "also log the decisions of the parser: detected format, ATQA byte order, card size, missing blocks")
+	fs.BoolVar(&cfg.Quiet, "quiet", false, "only print errors")
+	fs.Usage = func() {
+		_, _ = fmt.Fprintf(fs.Output(), "Usage of %s serve:\n", os.Args[0])
+		fs.PrintDefaults()
+	}
+	usage = fs.Usage
+	if err := applyDefaults(fs); err != nil {
+		return err
+	}
+	_ = fs.Parse(args)
+	if err := cfg.applyLogLevel(); err != nil {
+		return err
+	}
+
+	if cfg.Jobs < 1 {
+		return usageError(fmt.Sprintf("invalid number of jobs %d, expecting at least 1", cfg.Jobs))
+	}
+	if maxSize < 1 || maxSize > convert.MaxDumpSize {
+		return usageError(fmt.Sprintf("invalid maximum dump size %d, expecting 1 to %d bytes", maxSize, convert.MaxDumpSize))
+	}
+
+	srv := &http.Server{
+		Addr:              listen,
+		Handler:           newServeHandler(cfg.Jobs, maxSize),
+		ReadHeaderTimeout: timeout,
+		ReadTimeout:       timeout,
+		WriteTimeout:      timeout,
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	errc := make(chan error, 1)
+	go func() {
+		errc <- srv.ListenAndServe()
+	}()
+	if warningsEnabled() {
+		_, _ = fmt.Fprintf(os.Stderr, "serving on http://%s (POST /convert, POST /validate), Ctrl+C to stop\n", listen)
+	}
+
+	select {
+	case err := <-errc:
+		return err
+	case <-ctx.Done():
+	}
+	// let the requests in progress finish
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	return srv.Shutdown(shutdownCtx)
+}
+
+// Struct representing the HTTP service of the serve mode
+type server struct {
+	maxSize int64
+	slots   chan struct{} // one token per request handled in parallel
+}
+
+// Function that returns the handler of the serve mode, converting and validating at most jobs dumps at a time
+func newServeHandler(jobs int, maxSize int64) http.Handler {
+	s := &server{maxSize: maxSize, slots: make(chan struct{}, jobs)}
+
+	mux := http.NewServeMux()
+	mux.Handle("/convert", s.limit(s.handleConvert))
+	mux.Handle("/validate", s.limit(s.handleValidate))
+	mux.HandleFunc("/healthz", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.WriteString(w, "ok\n")
+	})
+	return logRequests(mux)
+}
+
+// Function that wraps a POST handler so that it waits for a free slot, and gives up when the client leaves
+func (s *server) limit(h http.HandlerFunc) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			w.Header().Set("Allow", http.MethodPost)
+			writeHTTPError(w, http.StatusMethodNotAllowed, errors.New("expecting a POST request with the dump as body"))
+			return
+		}
+		select {
+		case s.slots <- struct{}{}:
+			defer func() { <-s.slots }()
+		case <-r.Context().Done():
+			return
+		}
+		h(w, r)
+	})
+}
+
+// Function that handles POST /convert: parses the dump of the request and responds with the converted file
+func (s *server) handleConvert(w http.ResponseWriter, r *http.Request) {
+	cfg, err := requestConfig(r.URL.Query())
+	if err != nil {
+		writeHTTPError(w, http.StatusBadRequest, err)
+		return
+	}
+	name, data, status, err := s.readDump(w, r)
+	if err != nil {
+		writeHTTPError(w, status, err)
+		return
+	}
+	card, err := convert.ParseWithOptions(bytes.NewReader(data), requestParseOptions(cfg, r))
+	if err != nil {
+		writeHTTPError(w, http.StatusUnprocessableEntity, err)
+		return
+	}
+
+	var out bytes.Buffer
+	if err := convert.WriteCard(&out, card, cfg.writeOptions()); err != nil {
+		writeHTTPError(w, http.StatusUnprocessableEntity, err)
+		return
+	}
+
+	format := cfg.outputFormat()
+	contentType := "text/plain; charset=utf-8"
+	switch format {
+	case convert.OutputProxmark3JSON, convert.OutputChameleon:
+		contentType = "application/json"
+	case convert.OutputBin:
+		contentType = "application/octet-stream"
+	}
+	fileName := strings.TrimSuffix(name, filepath.Ext(name)) + convert.OutputExt(card, format)
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": fileName}))
+	w.Header().Set("X-Card-Type", newConversionReport(conversionResult{Card: card}).CardType)
+	_, _ = w.Write(out.Bytes())
+}
+
+// Struct representing the response of POST /validate: the report of the dump and the problems found in it
+type validationReport struct {
+	conversionReport
+	Issues []string `json:"issues,omitempty"`
+	Valid  bool     `json:"valid"`
+}
+
+// Function that handles POST /validate: parses and checks the dump of the request and responds with its JSON report
+func (s *server) handleValidate(w http.ResponseWriter, r *http.Request) {
+	cfg, err := requestConfig(r.URL.Query())
+	if err != nil {
+		writeHTTPError(w, http.StatusBadRequest, err)
+		return
+	}
+	name, data, status, err := s.readDump(w, r)
+	if err != nil {
+		writeHTTPError(w, status, err)
+		return
+	}
+
+	card, err := convert.ParseWithOptions(bytes.NewReader(data), requestParseOptions(cfg, r))
+	if err != nil {
+		report := validationReport{conversionReport: newConversionReport(conversionResult{Input: name, Err: err})}
+		writeJSON(w, http.StatusUnprocessableEntity, report)
+		return
+	}
+	report := validationReport{conversionReport: newConversionReport(conversionResult{Input: name, Card: card})}
+	if mc, ok := card.(*convert.MifareCard); ok {
+		for _, issue := range convert.ValidateMifare(mc) {
+			report.Issues = append(report.Issues, issue.String())
+		}
+	}
+	report.Valid = len(report.Issues) == 0
+	writeJSON(w, http.StatusOK, report)
+}
+
+// Function that reads the dump of a request, sent as the request body or as the "dump" file of a multipart form,
+// and returns its file name, data and the status to respond with when it cannot be read
+func (s *server) readDump(w http.ResponseWriter, r *http.Request) (string, []byte, int, error) {
+	r.Body = http.MaxBytesReader(w, r.Body, s.maxSize)
+
+	name := r.URL.Query().Get("name")
+	var body io.Reader = r.Body
+	if mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type")); mediaType == "multipart/form-data" {
+		f, header, err := r.FormFile("dump")
+		if err != nil {
+			return "", nil, requestErrorStatus(err), fmt.Errorf("failed to read the 'dump' file of the form: %w", err)
+		}
+		defer f.Close()
+		if name == "" {
+			name = filepath.Base(header.Filename)
+		}
+		body = f
+	}
+	if name == "" {
+		name = "dump"
+	}
+
+	data, err := io.ReadAll(body)
+	if err != nil {
+		return "", nil, requestErrorStatus(err), fmt.Errorf("failed to read dump: %w", err)
+	}
+	if len(data) == 0 {
+		return "", nil, http.StatusBadRequest, errors.New("empty dump, expecting the dump as request body")
+	}
+	return name, data, http.StatusOK, nil
+}
+
+// Function that returns the status to respond with when a request body cannot be read
+func requestErrorStatus(err error) int {
+	var tooLarge *http.MaxBytesError
+	if errors.As(err, &tooLarge) {
+		return http.StatusRequestEntityTooLarge
+	}
+	return http.StatusBadRequest
+}
+
+// Function that reads the conversion settings of a request from its query parameters, named like the flags of convert
+func requestConfig(q url.Values) (*config, error) {
+	cfg := &config{
+		InputFormat:   q.Get("input-format"),
+		OutputFormat:  q.Get("output-format"),
+		FormatVersion: convert.NFCFormatLatest,
+	}
+	if cfg.InputFormat == "" {
+		cfg.InputFormat = string(convert.FormatAuto)
+	}
+	for _, f := range []struct {
+		name string
+		dst  *bool
+	}{
+		{"swap-atqa", &cfg.SwapATQA},
+		{"strict", &cfg.Strict},
+		{"lenient", &cfg.Lenient},
+		{"annotate-access", &cfg.AnnotateAccess},
+	} {
+		if v := q.Get(f.name); v != "" {
+			b, err := strconv.ParseBool(v)
+			if err != nil {
+				return nil, fmt.Errorf("invalid %s value '%s', expecting true or false", f.name, v)
+			}
+			*f.dst = b
+		}
+	}
+	if v := q.Get("format-version"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < convert.NFCFormatV2 || n > convert.NFCFormatV4 {
+			return nil, fmt.Errorf("unsupported Flipper NFC format version '%s', expecting 2, 3 or 4", v)
+		}
+		cfg.FormatVersion = n
+	}
+
+	if err := cfg.checkInputFormat(); err != nil {
+		return nil, err
+	}
+	if err := cfg.checkOutputFormat(); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+// Function that returns the parser options of a request, logging the decisions of the parser with its client address
+func requestParseOptions(cfg *config, r *http.Request) convert.ParseOptions {
+	opts := cfg.parseOptions()
+	opts.Logger = logger.With("remote", r.RemoteAddr)
+	return opts
+}
+
+// Function that responds with a JSON value
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	_ = enc.Encode(v)
+}
+
+// Function that responds with an error as a JSON object
+func writeHTTPError(w http.ResponseWriter, status int, err error) {
+	writeJSON(w, status, struct {
+		Error string `json:"error"`
+	}{err.Error()})
+}
+
+// Struct wrapping a ResponseWriter to remember the status of the response
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+// WriteHeader method for statusRecorder to remember the status before sending it
+func (s *statusRecorder) WriteHeader(status int) {
+	s.status = status
+	s.ResponseWriter.WriteHeader(status)
+}
+
+// Function that wraps a handler to log every request with its status and duration
+func logRequests(h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		rec := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+		h.ServeHTTP(rec, r)
+		logger.Info("request", "method", r.Method, "path", r.URL.Path, "remote", r.RemoteAddr,
+			"status", rec.status, "took", time.Since(start).Round(time.Microsecond))
+	})
+}
@@ -0,0 +1,147 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// Function that reads a dump of the seed corpus of pkg/convert
+func readTestDump(t *testing.T, name string) []byte {
+	t.Helper()
+	data, err := os.ReadFile(filepath.Join("pkg", "convert", "testdata", name))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return data
+}
+
+// Function that returns a multipart form holding a dump as its "dump" file, with the form's content type
+func multipartDump(t *testing.T, fileName string, data []byte) (string, []byte) {
+	t.Helper()
+	var buf bytes.Buffer
+	mw := multipart.NewWriter(&buf)
+	fw, err := mw.CreateFormFile("dump", fileName)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := fw.Write(data); err != nil {
+		t.Fatal(err)
+	}
+	if err := mw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return mw.FormDataContentType(), buf.Bytes()
+}
+
+func TestServeHandler(t *testing.T) {
+	const maxSize = 8 << 10
+	dump := readTestDump(t, "hf-mf-11223344-dump.json")
+	formType, form := multipartDump(t, "lab/card one.json", dump)
+	bigFormType, bigForm := multipartDump(t, "big.json", bytes.Repeat([]byte("0"), maxSize+1))
+
+	tests := []struct {
+		name        string
+		method      string
+		target      string
+		contentType string
+		body        []byte
+		wantStatus  int
+		wantFile    string // file name of Content-Disposition
+		wantError   string // part of the error of the JSON response
+	}{
+		{"get convert", http.MethodGet, "/convert", "", nil, http.StatusMethodNotAllowed, "", "expecting a POST request"},
+		{"put validate", http.MethodPut, "/validate", "", dump, http.StatusMethodNotAllowed, "", "expecting a POST request"},
+		{"empty body", http.MethodPost, "/convert", "", nil, http.StatusBadRequest, "", "empty dump"},
+		{"empty body validate", http.MethodPost, "/validate", "", nil, http.StatusBadRequest, "", "empty dump"},
+		{"body over max size", http.MethodPost, "/convert", "", bytes.Repeat([]byte("0"), maxSize+1), http.StatusRequestEntityTooLarge, "", "failed to read dump"},
+		{"form over max size", http.MethodPost, "/convert", bigFormType, bigForm, http.StatusRequestEntityTooLarge, "", "'dump' file"},
+		{"form without dump", http.MethodPost, "/convert", "multipart/form-data; boundary=x", []byte("--x--\r\n"), http.StatusBadRequest, "", "'dump' file"},
+		{"body", http.MethodPost, "/convert", "", dump, http.StatusOK, "dump.nfc", ""},
+		{"body named", http.MethodPost, "/convert?name=card.json", "", dump, http.StatusOK, "card.nfc", ""},
+		{"body as eml", http.MethodPost, "/convert?name=card.json&output-format=eml", "", dump, http.StatusOK, "card.eml", ""},
+		{"multipart dump", http.MethodPost, "/convert", formType, form, http.StatusOK, "card one.nfc", ""},
+		{"multipart dump named", http.MethodPost, "/convert?name=other.json", formType, form, http.StatusOK, "other.nfc", ""},
+		{"bad bool", http.MethodPost, "/convert?strict=yes please", "", dump, http.StatusBadRequest, "", "invalid strict value"},
+		{"bad format version", http.MethodPost, "/convert?format-version=5", "", dump, http.StatusBadRequest, "", "unsupported Flipper NFC format version"},
+		{"bad output format", http.MethodPost, "/convert?output-format=xml", "", dump, http.StatusBadRequest, "", "xml"},
+		{"bad input format", http.MethodPost, "/validate?input-format=xml", "", dump, http.StatusBadRequest, "", "unsupported input format"},
+		{"strict and lenient", http.MethodPost, "/convert?strict=true&lenient=true", "", dump, http.StatusBadRequest, "", "cannot be used together"},
+		{"unparseable dump", http.MethodPost, "/convert", "", []byte("not a dump"), http.StatusUnprocessableEntity, "", "unrecognised dump format"},
+		{"lf key as eml", http.MethodPost, "/convert?output-format=eml", "", readTestDump(t, "em4100.rfid"), http.StatusUnprocessableEntity, "", "only holds Mifare Classic cards"},
+	}
+
+	h := newServeHandler(2, maxSize)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, strings.ReplaceAll(tt.target, " ", "%20"), bytes.NewReader(tt.body))
+			if tt.contentType != "" {
+				req.Header.Set("Content-Type", tt.contentType)
+			}
+			rec := httptest.NewRecorder()
+			h.ServeHTTP(rec, req)
+
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("got status %d, want %d: %s", rec.Code, tt.wantStatus, rec.Body)
+			}
+			if tt.wantFile != "" {
+				_, params, err := mime.ParseMediaType(rec.Header().Get("Content-Disposition"))
+				if err != nil || params["filename"] != tt.wantFile {
+					t.Fatalf("got Content-Disposition %q, want file name %q", rec.Header().Get("Content-Disposition"), tt.wantFile)
+				}
+			}
+			if tt.wantError != "" {
+				var resp struct {
+					Error string `json:"error"`
+				}
+				if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil || !strings.Contains(resp.Error, tt.wantError) {
+					t.Fatalf("got response %s, want an error containing %q", rec.Body, tt.wantError)
+				}
+			}
+		})
+	}
+}
+
+func TestServeValidate(t *testing.T) {
+	tests := []struct {
+		name       string
+		target     string
+		body       []byte
+		wantStatus int
+		wantValid  bool
+		wantType   string
+		wantError  string
+	}{
+		{"valid dump", "/validate?name=card.json", readTestDump(t, "hf-mf-11223344-dump.json"), http.StatusOK, true, "Mifare Classic 1K", ""},
+		{"lf key", "/validate", readTestDump(t, "em4100.rfid"), http.StatusOK, true, "EM4100", ""},
+		{"unparseable dump", "/validate?name=junk.txt", []byte("not a dump"), http.StatusUnprocessableEntity, false, "", "unrecognised dump format"},
+	}
+
+	h := newServeHandler(1, 64<<10)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			h.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, tt.target, bytes.NewReader(tt.body)))
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("got status %d, want %d: %s", rec.Code, tt.wantStatus, rec.Body)
+			}
+			if ct := rec.Header().Get("Content-Type"); ct != "application/json" {
+				t.Fatalf("got Content-Type %q, want a JSON report", ct)
+			}
+			var report validationReport
+			if err := json.Unmarshal(rec.Body.Bytes(), &report); err != nil {
+				t.Fatalf("invalid JSON report: %v\n%s", err, rec.Body)
+			}
+			if report.Valid != tt.wantValid || report.CardType != tt.wantType || !strings.Contains(report.Error, tt.wantError) {
+				t.Fatalf("got report %s", rec.Body)
+			}
+		})
+	}
+}
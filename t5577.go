@@ -0,0 +1,31 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/dimchansky/proxmark3-to-flipper/pkg/convert"
+)
+
+// Function that creates the T5577 blocks file, or uses standard output for "-", and writes the blocks
+// cloning the LF key to it
+func writeT5577File(cfg *config, c convert.Card) error {
+	lc, ok := c.(*convert.LFCard)
+	if !ok {
+		return fmt.Errorf("T5577 blocks can only be written for LF keys")
+	}
+	blocks, err := convert.T5577Data(lc)
+	if err != nil {
+		return err
+	}
+
+	format := convert.T5577Format(cfg.T5577Format)
+	if cfg.T5577File == stdioFileName {
+		return convert.WriteT5577(os.Stdout, blocks, format)
+	}
+
+	return writeFileAtomic(cfg.T5577File, "T5577 file", cfg.Force, func(w io.Writer) error {
+		return convert.WriteT5577(w, blocks, format)
+	})
+}
@@ -0,0 +1,156 @@
+package main
+
+import (
+	"embed"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/template"
+
+	"github.com/dimchansky/proxmark3-to-flipper/pkg/convert"
+)
+
+// Example templates shipped with the program, selected by name with -template. Their file names give the
+// extension of the output files like for template files, keys.csv.tmpl being the keys template writing .csv files
+//
+//go:embed templates/*.tmpl
+var embeddedTemplates embed.FS
+
+// Struct holding the data a -template is rendered with
+type templateData struct {
+	Input   string       // dump the card was read from
+	Type    string       // card type, e.g. "Mifare Classic 1K" or "EM4100"
+	UID     string       // UID of the card, empty for LF keys
+	Version string       // version of the program
+	Card    convert.Card // parsed card: *convert.MifareCard, *convert.LFCard, *convert.FelicaCard...
+}
+
+// Struct representing a Mifare Classic sector in templates
+type templateSector struct {
+	Number int
+	Blocks []templateBlock
+}
+
+// Struct representing a Mifare Classic block in templates
+type templateBlock struct {
+	Number  int
+	Trailer bool
+	Block   convert.Block
+}
+
+// Function that returns the Mifare Classic card of a template, failing the rendering for other cards
+func templateMifare(c convert.Card) (*convert.MifareCard, error) {
+	mc, ok := c.(*convert.MifareCard)
+	if !ok {
+		return nil, errors.New("template needs a Mifare Classic card")
+	}
+	return mc, nil
+}
+
+// Functions available to -template templates
+var templateFuncs = template.FuncMap{
+	// hex data or block without spaces, unknown bytes as ??
+	"hex": func(v fmt.Stringer) string {
+		return strings.ReplaceAll(v.String(), " ", "")
+	},
+	"replace": strings.ReplaceAll,
+	"lower":   strings.ToLower,
+	"upper":   strings.ToUpper,
+	"sectors": func(c convert.Card) ([]templateSector, error) {
+		mc, err := templateMifare(c)
+		if err != nil {
+			return nil, err
+		}
+		sectors := make([]templateSector, convert.ClassicSectorCount(len(mc.Blocks)))
+		for s := range sectors {
+			sectors[s].Number = s
+			first := convert.ClassicSectorFirstBlock(s)
+			for b := first; b < first+convert.ClassicSectorBlocks(s); b++ {
+				sectors[s].Blocks = append(sectors[s].Blocks, templateBlock{Number: b, Trailer: b == convert.ClassicSectorTrailer(s), Block: mc.Blocks[b]})
+			}
+		}
+		return sectors, nil
+	},
+	"sectorKeys": func(c convert.Card) ([]convert.SectorKeys, error) {
+		mc, err := templateMifare(c)
+		if err != nil {
+			return nil, err
+		}
+		return mc.SectorKeys(), nil
+	},
+}
+
+// Function that lists the names of the embedded templates for the help text
+func embeddedTemplateList() string {
+	entries, _ := embeddedTemplates.ReadDir("templates")
+	names := make([]string, 0, len(entries))
+	for _, e := range entries {
+		name, _ := embeddedTemplateName(e.Name())
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return strings.Join(names, ", ")
+}
+
+// Function that splits the file name of an embedded template into the name selecting it and the extension
+// of its output files, e.g. keys.csv.tmpl into keys and .csv
+func embeddedTemplateName(fileName string) (string, string) {
+	name, ext, _ := strings.Cut(strings.TrimSuffix(fileName, ".tmpl"), ".")
+	return name, "." + ext
+}
+
+// Function that loads the -template: the name of an embedded template or a template file. The
+// extension of the output files is the one before .tmpl in the name of the template file
+func loadTemplate(name string) (*template.Template, string, error) {
+	entries, _ := embeddedTemplates.ReadDir("templates")
+	for _, e := range entries {
+		embeddedName, ext := embeddedTemplateName(e.Name())
+		if embeddedName != name {
+			continue
+		}
+		data, err := embeddedTemplates.ReadFile("templates/" + e.Name())
+		if err != nil {
+			return nil, "", err
+		}
+		tmpl, err := template.New(name).Funcs(templateFuncs).Parse(string(data))
+		return tmpl, ext, err
+	}
+
+	data, err := os.ReadFile(name)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to read template '%s', not an embedded template (%s) either: %w", name, embeddedTemplateList(), err)
+	}
+	tmpl, err := template.New(filepath.Base(name)).Funcs(templateFuncs).Parse(string(data))
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to parse template '%s': %w", name, err)
+	}
+	ext := filepath.Ext(strings.TrimSuffix(name, ".tmpl"))
+	if ext == "" {
+		ext = ".txt"
+	}
+	return tmpl, ext, nil
+}
+
+// Function that renders a card converted from the input through the -template
+func renderTemplate(w io.Writer, cfg *config, input string, c convert.Card) error {
+	r := newConversionReport(conversionResult{Card: c})
+	data := templateData{Input: input, Type: r.CardType, UID: r.UID, Version: Version, Card: c}
+	if err := cfg.template.Execute(w, data); err != nil {
+		return fmt.Errorf("failed to render template: %w", err)
+	}
+	return nil
+}
+
+// Function that renders a card through the -template into the output file, or standard output for "-"
+func writeTemplateFile(cfg *config, input, output string, c convert.Card) error {
+	if output == stdioFileName {
+		return renderTemplate(os.Stdout, cfg, input, c)
+	}
+	return writeFileAtomic(output, "output file", cfg.Force, func(w io.Writer) error {
+		return renderTemplate(w, cfg, input, c)
+	})
+}
@@ -0,0 +1,269 @@
+package main
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/dimchansky/proxmark3-to-flipper/pkg/convert"
+)
+
+// Struct representing a dump listed by the interactive mode
+type tuiEntry struct {
+	Input batchInput
+	Card  convert.Card
+	Err   error
+}
+
+// Struct holding the state of the interactive mode
+type tui struct {
+	cfg     *config
+	entries []tuiEntry
+	color   bool
+	out     io.Writer
+}
+
+// Function that runs the interactive mode: lists the dumps of a directory, previews them and converts
+// or exports the selected ones
+func runTUI(args []string) error {
+	var (
+		cfg       config
+		colorMode string
+	)
+	fs := flag.NewFlagSet("tui", flag.ExitOnError)
+	fs.StringVar(&cfg.OutputDir, "output-dir", ".", "directory the converted and exported files are written to")
+	fs.IntVar(&cfg.FormatVersion, "format-version", convert.NFCFormatLatest, "Flipper NFC file format version to write (2, 3 or 4)")
+	fs.BoolVar(&cfg.SwapATQA, "swap-atqa", false, "swap the ATQA bytes of the dumps instead of detecting their order")
+	fs.StringVar(&cfg.InputFormat, "input-format", string(convert.FormatAuto), "input format, detected from the content by default: "+inputFormatList())
+	fs.BoolVar(&cfg.Strict, "strict", false, "reject JSON dumps which are not written the way the official Proxmark3 client writes them")
+	fs.BoolVar(&cfg.Lenient, "lenient", false, "accept JSON dumps of Proxmark3 forks: hex with spaces, missing headers, blocks keyed as \"Block N\"")
+	fs.StringVar(&colorMode, "color", "auto", "colorize the sector map: auto, always or never")
+	fs.BoolVar(&cfg.Force, "f", false, "overwrite existing output files, which are left untouched otherwise")
+	fs.BoolVar(&cfg.Force, "force", false, "same as -f")
+	fs.Usage = func() {
+		_, _ = fmt.Fprintf(fs.Output(), "Usage of %s tui [flags] [DIR]:\n", os.Args[0])
+		fs.PrintDefaults()
+	}
+	usage = fs.Usage
+	if err := applyDefaults(fs); err != nil {
+		return err
+	}
+	_ = fs.Parse(args)
+	if err := cfg.checkInputFormat(); err != nil {
+		return err
+	}
+
+	dir := "."
+	switch fs.NArg() {
+	case 0:
+	case 1:
+		dir = fs.Arg(0)
+	default:
+		return usageError("please provide a single directory to browse")
+	}
+	color, err := useColor(colorMode, os.Stdout)
+	if err != nil {
+		return err
+	}
+
+	files, err := collectBatchInputs(dir)
+	if err != nil {
+		return err
+	}
+	if len(files) == 0 {
+		return fmt.Errorf("no Proxmark3 dump files found in '%s'", dir)
+	}
+
+	t := &tui{cfg: &cfg, color: color, out: os.Stdout}
+	for _, f := range files {
+		card, err := parseProxMark3File(f.Path, cfg.parseOptions())
+		t.entries = append(t.entries, tuiEntry{Input: f, Card: card, Err: err})
+	}
+	return t.run(os.Stdin)
+}
+
+// Function that reads the commands of the interactive mode until quit or end of input
+func (t *tui) run(in io.Reader) error {
+	t.list()
+	t.help()
+
+	sc := bufio.NewScanner(in)
+	for {
+		_, _ = fmt.Fprint(t.out, "> ")
+		if !sc.Scan() {
+			_, _ = fmt.Fprintln(t.out)
+			return sc.Err()
+		}
+		fields := strings.Fields(sc.Text())
+		if len(fields) == 0 {
+			continue
+		}
+
+		cmd, args := fields[0], fields[1:]
+		if _, err := strconv.Atoi(cmd); err == nil {
+			cmd, args = "p", fields
+		}
+		switch cmd {
+		case "l", "list":
+			t.list()
+		case "p", "preview":
+			t.forEach(args, t.preview)
+		case "c", "convert":
+			t.forEach(args, func(i int) { t.export(i, convert.OutputFlipper) })
+		case "e", "export":
+			if len(args) == 0 {
+				t.printf("please give the export format: %s\n", outputFormatList())
+				continue
+			}
+			format := convert.OutputFormat(args[0])
+			if err := (&config{OutputFormat: args[0]}).checkOutputFormat(); err != nil {
+				t.printf("%v\n", err)
+				continue
+			}
+			t.forEach(args[1:], func(i int) { t.export(i, format) })
+		case "h", "help", "?":
+			t.help()
+		case "q", "quit", "exit":
+			return nil
+		default:
+			t.printf("unknown command '%s'\n", cmd)
+		}
+	}
+}
+
+// Function that prints formatted text to the interactive output
+func (t *tui) printf(format string, args ...interface{}) {
+	_, _ = fmt.Fprintf(t.out, format, args...)
+}
+
+// Function that prints the commands of the interactive mode
+func (t *tui) help() {
+	t.printf(`commands:
+  N | p N...        preview dumps
+  c N... | c all    convert to Flipper files in %s
+  e FMT N...        export as %s
+  l                 list dumps
+  q                 quit
+`, t.cfg.OutputDir, outputFormatList())
+}
+
+// Function that lists the dumps with their card type, UID and completeness
+func (t *tui) list() {
+	for i, e := range t.entries {
+		if e.Err != nil {
+			t.printf("%3d  %-40s %s\n", i+1, e.Input.Rel, colorize(t.color, ansiRed, "error: "+e.Err.Error()))
+			continue
+		}
+		r := newConversionReport(conversionResult{Card: e.Card})
+		blocks := ""
+		if r.Blocks > 0 {
+			blocks = fmt.Sprintf("%d/%d blocks", r.KnownBlocks, r.Blocks)
+			if r.UnknownBlocks > 0 {
+				blocks = colorize(t.color, ansiRed, blocks)
+			} else {
+				blocks = colorize(t.color, ansiGreen, blocks)
+			}
+		}
+		id := r.UID
+		if id == "" {
+			id = r.Data
+		}
+		t.printf("%3d  %-40s %-20s %-26s %s\n", i+1, e.Input.Rel, r.CardType, id, blocks)
+	}
+}
+
+// Function that calls fn with the index of every dump selected by the arguments, "all" selecting every dump
+func (t *tui) forEach(args []string, fn func(int)) {
+	if len(args) == 0 {
+		t.printf("please select dumps by number\n")
+		return
+	}
+	if len(args) == 1 && args[0] == "all" {
+		for i := range t.entries {
+			fn(i)
+		}
+		return
+	}
+	for _, a := range args {
+		n, err := strconv.Atoi(a)
+		if err != nil || n < 1 || n > len(t.entries) {
+			t.printf("no dump number '%s'\n", a)
+			continue
+		}
+		fn(n - 1)
+	}
+}
+
+// Function that prints the identification, warnings and sector map of a dump
+func (t *tui) preview(i int) {
+	e := t.entries[i]
+	t.printf("%s\n", colorize(t.color, ansiBold, e.Input.Path))
+	if e.Err != nil {
+		t.printf("  error: %v\n", e.Err)
+		return
+	}
+
+	r := newConversionReport(conversionResult{Card: e.Card})
+	t.printf("  type: %s\n", r.CardType)
+	mc, ok := e.Card.(*convert.MifareCard)
+	if !ok {
+		if r.UID != "" {
+			t.printf("  UID:  %s\n", r.UID)
+		}
+		if r.Data != "" {
+			t.printf("  data: %s\n", r.Data)
+		}
+		return
+	}
+
+	t.printf("  UID:  %s   ATQA: %s   SAK: %s\n", mc.UID, mc.ATQA, mc.SAK)
+	for _, w := range mc.Warnings {
+		t.printf("  warning: %s\n", w)
+	}
+	t.printf("  sector map (%s known, %s partial, %s unknown):\n",
+		colorize(t.color, ansiGreen, "#"), colorize(t.color, ansiYellow, "~"), colorize(t.color, ansiRed, "?"))
+	for s := 0; s < convert.ClassicSectorCount(len(mc.Blocks)); s++ {
+		var sb strings.Builder
+		first := convert.ClassicSectorFirstBlock(s)
+		for b := first; b < first+convert.ClassicSectorBlocks(s); b++ {
+			block := mc.Blocks[b]
+			switch {
+			case block.IsComplete():
+				sb.WriteString(colorize(t.color, ansiGreen, "#"))
+			case block.UnknownCount() < len(block.Data):
+				sb.WriteString(colorize(t.color, ansiYellow, "~"))
+			default:
+				sb.WriteString(colorize(t.color, ansiRed, "?"))
+			}
+		}
+		t.printf("  %2d  %s\n", s, sb.String())
+	}
+}
+
+// Function that writes a dump into the output directory in the given format
+func (t *tui) export(i int, format convert.OutputFormat) {
+	e := t.entries[i]
+	if e.Err != nil {
+		t.printf("%s: cannot convert, %v\n", e.Input.Rel, e.Err)
+		return
+	}
+
+	opts := t.cfg.writeOptions()
+	opts.Format = format
+	name := strings.TrimSuffix(e.Input.Rel, filepath.Ext(e.Input.Rel)) + convert.OutputExt(e.Card, format)
+	out := filepath.Join(t.cfg.OutputDir, name)
+	if err := os.MkdirAll(filepath.Dir(out), 0o755); err != nil {
+		t.printf("%s: %v\n", e.Input.Rel, err)
+		return
+	}
+	if err := writeOutputFile(out, e.Card, opts, t.cfg.Force); err != nil {
+		t.printf("%s: %v\n", e.Input.Rel, err)
+		return
+	}
+	t.printf("%s -> %s\n", e.Input.Rel, out)
+}
@@ -0,0 +1,94 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/dimchansky/proxmark3-to-flipper/internal/flipper"
+	"github.com/dimchansky/proxmark3-to-flipper/internal/serialport"
+)
+
+// Directories of the Flipper SD card the apps load their files from, by file extension
+var flipperAppDirs = map[string]string{
+	".nfc":  "/ext/nfc",
+	".shd":  "/ext/nfc",
+	".keys": "/ext/nfc/.cache",
+	".rfid": "/ext/lfrfid",
+}
+
+// Function that uploads converted files to a Flipper Zero connected on the port, detected when empty,
+// into the directory of the app which opens them
+func uploadToFlipper(port string, files []string) error {
+	dests, err := flipperUploadPaths(files)
+	if err != nil {
+		return err
+	}
+
+	if port == "" {
+		p, err := serialport.Detect(serialport.Flipper)
+		if err != nil {
+			return fmt.Errorf("cannot detect the Flipper port, please provide -flipper-port: %w", err)
+		}
+		port = p
+	}
+
+	sp, err := serialport.Open(port)
+	if err != nil {
+		return err
+	}
+	defer sp.Close()
+
+	cli, err := flipper.NewCLI(sp)
+	if err != nil {
+		return err
+	}
+
+	for i, f := range files {
+		data, err := os.ReadFile(f)
+		if err != nil {
+			return fmt.Errorf("failed to read '%s' for upload: %w", f, err)
+		}
+		dest := dests[i]
+		if err := cli.WriteFile(dest, data); err != nil {
+			return fmt.Errorf("failed to upload '%s' to the Flipper: %w", f, err)
+		}
+		_, _ = fmt.Fprintf(os.Stderr, "uploaded %s to %s on %s\n", f, dest, port)
+	}
+	return nil
+}
+
+// Function that returns the path on the Flipper every file is uploaded to, in the directory of its app.
+// Two files with different contents which would be uploaded to the same path are refused before anything is
+// uploaded, since the second one would silently replace the first. The key caches of a card written for
+// dumps in several directories hold the same keys and are no conflict
+func flipperUploadPaths(files []string) ([]string, error) {
+	dests := make([]string, len(files))
+	sources := make(map[string]string, len(files))
+	for i, f := range files {
+		dir, ok := flipperAppDirs[strings.ToLower(filepath.Ext(f))]
+		if !ok {
+			return nil, fmt.Errorf("don't know where to upload '%s' on the Flipper", f)
+		}
+		dests[i] = path.Join(dir, filepath.Base(f))
+		key := strings.ToLower(dests[i])
+		if prev, ok := sources[key]; ok && !sameFileContent(prev, f) {
+			return nil, fmt.Errorf("'%s' and '%s' would be uploaded to the same file %s on the Flipper, rename one of them", prev, f, dests[i])
+		}
+		sources[key] = f
+	}
+	return dests, nil
+}
+
+// Function that reports whether two files can be read and hold the same bytes
+func sameFileContent(a, b string) bool {
+	if filepath.Clean(a) == filepath.Clean(b) {
+		return true
+	}
+	da, errA := os.ReadFile(a)
+	db, errB := os.ReadFile(b)
+	return errA == nil && errB == nil && bytes.Equal(da, db)
+}
@@ -0,0 +1,45 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestFlipperUploadPaths(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, content string) string {
+		name = filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(name), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(name, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		return name
+	}
+	cardA := write(filepath.Join("a", "card.nfc"), "a")
+	cardB := write(filepath.Join("b", "card.nfc"), "b")
+	key := write("key.rfid", "key")
+	keysA := write(filepath.Join("a", ".cache", "11223344.keys"), "keys")
+	keysB := write(filepath.Join("b", ".cache", "11223344.keys"), "keys")
+
+	dests, err := flipperUploadPaths([]string{cardA, key, keysA, keysB, keysA})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"/ext/nfc/card.nfc", "/ext/lfrfid/key.rfid", "/ext/nfc/.cache/11223344.keys",
+		"/ext/nfc/.cache/11223344.keys", "/ext/nfc/.cache/11223344.keys"}
+	if !reflect.DeepEqual(dests, want) {
+		t.Fatalf("got %v, want %v", dests, want)
+	}
+
+	if _, err := flipperUploadPaths([]string{cardA, cardB}); err == nil || !strings.Contains(err.Error(), "/ext/nfc/card.nfc") {
+		t.Fatalf("got error %v, want the conflict on /ext/nfc/card.nfc", err)
+	}
+	if _, err := flipperUploadPaths([]string{write("dump.json", "{}")}); err == nil {
+		t.Fatal("expecting an error for a file no app opens")
+	}
+}
@@ -0,0 +1,94 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/dimchansky/proxmark3-to-flipper/pkg/convert"
+)
+
+// Function that runs the validate mode: checks a dump for structural correctness without converting it
+func runValidate(args []string) error {
+	var (
+		cfg    config
+		access bool
+	)
+	fs := flag.NewFlagSet("validate", flag.ExitOnError)
+	fs.StringVar(&cfg.InputFile, "i", "", "input Proxmark3 dump file to validate, '-' for stdin")
+	fs.BoolVar(&cfg.SwapATQA, "swap-atqa", false, "swap the ATQA bytes of the dump instead of detecting their order")
+	fs.StringVar(&cfg.InputFormat, "input-format", string(convert.FormatAuto), "input format, detected from the content by default: "+inputFormatList())
+	fs.BoolVar(&cfg.Lenient, "lenient", false, "accept JSON dumps of Proxmark3 forks: hex with spaces, missing headers, blocks keyed as \"Block N\"")
+	fs.BoolVar(&cfg.Strict, "strict", false, "treat every problem found as an error and reject non-canonical JSON dumps")
+	fs.BoolVar(&cfg.Verbose, "v", false, "log what is parsed and written to standard error")
+	fs.BoolVar(&cfg.Debug, "vv", false, "also log the decisions of the parser: detected format, ATQA byte order, card size, missing blocks")
+	fs.BoolVar(&cfg.Quiet, "quiet", false, "only print errors, no warnings or hints")
+	fs.BoolVar(&access, "access", false, "print the decoded access conditions of every sector")
+	fs.Usage = func() {
+		_, _ = fmt.Fprintf(fs.Output(), "Usage of %s validate [flags] [DUMP]:\n", os.Args[0])
+		fs.PrintDefaults()
+	}
+	usage = fs.Usage
+	if err := applyDefaults(fs); err != nil {
+		return err
+	}
+	_ = fs.Parse(args)
+	if err := cfg.checkInputFormat(); err != nil {
+		return err
+	}
+
+	if err := cfg.applyLogLevel(); err != nil {
+		return err
+	}
+
+	var err error
+	if cfg.InputFile, err = inputArg(fs, cfg.InputFile); err != nil {
+		return err
+	}
+	if cfg.InputFile == "" {
+		return usageError("please provide input Proxmark3 dump file to validate")
+	}
+
+	card, err := parseProxMark3File(cfg.InputFile, cfg.parseOptions())
+	if err != nil {
+		return err
+	}
+
+	mc, ok := card.(*convert.MifareCard)
+	if !ok {
+		_, _ = fmt.Fprintf(os.Stderr, "%s: nothing to validate for %s files\n", cfg.InputFile, card.FlipperExt())
+		return nil
+	}
+
+	if access {
+		for _, sa := range mc.AccessConditions() {
+			if sa.Error != "" {
+				fmt.Printf("Sector %d: %s\n", sa.Sector, sa.Error)
+				continue
+			}
+			fmt.Printf("Sector %d\n", sa.Sector)
+			for _, line := range sa.Conditions {
+				fmt.Printf("  %s\n", line)
+			}
+		}
+	}
+
+	issues := convert.ValidateMifare(mc)
+	severity := "warning"
+	if cfg.Strict {
+		severity = "error"
+	}
+	for _, issue := range issues {
+		_, _ = fmt.Fprintf(os.Stderr, "%s: %s: %s\n", severity, cfg.InputFile, issue)
+	}
+
+	if len(issues) == 0 {
+		_, _ = fmt.Fprintf(os.Stderr, "%s: dump looks valid\n", cfg.InputFile)
+		return nil
+	}
+	if cfg.Strict {
+		return fmt.Errorf("%d problems found in '%s'", len(issues), cfg.InputFile)
+	}
+	_, _ = fmt.Fprintf(os.Stderr, "%s: %d problems found\n", cfg.InputFile, len(issues))
+	return nil
+}